@@ -11,14 +11,22 @@ import (
 var tpl = template.Must(template.New("generator").Parse(`package main
 
 import (
+	"log"
 	"os"
-	"path"
 
 	"github.com/fujitsueos/go-server-generator/generate"
 )
 
+// Run this in the folder of the swagger file, or pass the path of the swagger file as an argument
 func main() {
-	generate.FromSwagger(path.Join(os.Getenv("GOPATH"), "{{ .SwaggerPath }}"))
+	swaggerPath := {{ printf "%q" .SwaggerPath }}
+	if len(os.Args) > 1 {
+		swaggerPath = os.Args[1]
+	}
+
+	if err := generate.FromSwagger(swaggerPath); err != nil {
+		log.Fatal(err)
+	}
 }
 `))
 
@@ -66,11 +74,7 @@ func createOutputFile(swaggerPath, relativePath string) (file *os.File, closeFil
 	return
 }
 
+// the generated command finds the swagger file in the working directory, which is the folder of the swagger file
 func generateCode(swaggerPath string, file *os.File) error {
-	relSwaggerPath, err := filepath.Rel(os.Getenv("GOPATH"), swaggerPath)
-	if err != nil {
-		return err
-	}
-
-	return tpl.Execute(file, struct{ SwaggerPath string }{relSwaggerPath})
+	return tpl.Execute(file, struct{ SwaggerPath string }{filepath.Base(swaggerPath)})
 }
@@ -0,0 +1,190 @@
+package generate
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	openapierrors "github.com/go-openapi/errors"
+	"github.com/go-openapi/spec"
+	log "github.com/sirupsen/logrus"
+)
+
+// Options configure Generate and GenerateFromReader
+// They replace the global settings of SetLayout, SetModule, RegisterFormat and LoadConfig for one call
+type Options struct {
+	// Folder that the swagger file is in; the layout is relative to it
+	// When empty, the current working directory is used
+	Dir string
+	// Where the generated files are placed; empty fields get the default value
+	Layout Layout
+	// Import path of Dir, when there is no go.mod in Dir or one of its parents
+	Module string
+	// Custom string formats by name
+	Formats map[string]Format
+}
+
+// Diagnostic is a warning or error that was logged while generating
+type Diagnostic struct {
+	// Level is "warning", "error", "fatal" or "panic"
+	Level   string
+	Message string
+	// Fields describe where the problem is, e.g. the definition, property or route
+	Fields map[string]interface{}
+}
+
+// Result is the output of Generate and GenerateFromReader
+type Result struct {
+	// Formatted Go files by absolute path; nil when generating failed
+	Files map[string][]byte
+	// Warnings and errors, also when generating failed
+	Diagnostics []Diagnostic
+}
+
+// the generator keeps its settings in package variables, so only one generation can run at a time
+var generateMutex sync.Mutex
+
+// Generate generates code for a swagger spec that has already been loaded, without writing anything
+// Generate and GenerateFromReader can be called concurrently; other functions of this package cannot
+// be called at the same time
+func Generate(swagger *spec.Swagger, options Options) (result Result, err error) {
+	if swagger == nil {
+		err = errors.New("No swagger spec provided")
+		result.Diagnostics = []Diagnostic{{Level: log.ErrorLevel.String(), Message: err.Error()}}
+		return
+	}
+
+	var swaggerData []byte
+	if swaggerData, err = swagger.MarshalJSON(); err != nil {
+		result.Diagnostics = []Diagnostic{{Level: log.ErrorLevel.String(), Message: err.Error()}}
+		return
+	}
+
+	return generateFromData(swaggerData, options)
+}
+
+// GenerateFromReader generates code for a swagger spec in JSON or YAML, without writing anything
+// References to other files are relative to Options.Dir
+func GenerateFromReader(reader io.Reader, options Options) (result Result, err error) {
+	var swaggerData []byte
+	if swaggerData, err = ioutil.ReadAll(reader); err != nil {
+		result.Diagnostics = []Diagnostic{{Level: log.ErrorLevel.String(), Message: err.Error()}}
+		return
+	}
+
+	return generateFromData(swaggerData, options)
+}
+
+func generateFromData(swaggerData []byte, options Options) (result Result, err error) {
+	generateMutex.Lock()
+	defer generateMutex.Unlock()
+
+	hook := &diagnosticsHook{}
+	defer func() {
+		result.Diagnostics = append(result.Diagnostics, hook.diagnostics...)
+		// the validation errors of the spec are returned instead of logged
+		if composite, ok := err.(*openapierrors.CompositeError); ok {
+			for _, validationErr := range composite.Errors {
+				result.Diagnostics = append(result.Diagnostics, Diagnostic{Level: log.ErrorLevel.String(), Message: validationErr.Error()})
+			}
+		}
+	}()
+
+	defer useOptions()()
+
+	diagnosticsLogger := log.New()
+	diagnosticsLogger.Out = ioutil.Discard
+	diagnosticsLogger.Hooks.Add(hook)
+	logger = diagnosticsLogger
+
+	if err = applyOptions(&options); err != nil {
+		return
+	}
+
+	// the spec is not read from a file, so references to other files are relative to Dir
+	var (
+		swagger             *spec.Swagger
+		externalDefinitions map[string]string
+	)
+	if swagger, externalDefinitions, err = parseValidSwagger(swaggerData, filepath.Join(options.Dir, "swagger.yaml")); err != nil {
+		return
+	}
+
+	var files map[string][]byte
+	if files, err = generateServer(options.Dir, swaggerData, swagger, externalDefinitions); err != nil {
+		return
+	}
+
+	result.Files = files
+
+	return
+}
+
+// useOptions saves the global settings, and returns a function that restores them
+func useOptions() (restore func()) {
+	previousLayout, previousModule, previousFormats, previousLogger := layout, explicitModule, customFormats, logger
+
+	return func() {
+		layout, explicitModule, customFormats, logger = previousLayout, previousModule, previousFormats, previousLogger
+	}
+}
+
+func applyOptions(options *Options) (err error) {
+	if options.Dir == "" {
+		if options.Dir, err = os.Getwd(); err != nil {
+			logger.Error(err)
+			return
+		}
+	}
+	if options.Dir, err = filepath.Abs(options.Dir); err != nil {
+		logger.Error(err)
+		return
+	}
+
+	layout = defaultLayout
+	if err = SetLayout(options.Layout); err != nil {
+		return
+	}
+
+	SetModule(options.Module)
+
+	customFormats = map[string]Format{}
+	for name, format := range options.Formats {
+		if err = RegisterFormat(name, format); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// diagnosticsHook collects the warnings and errors that are logged
+type diagnosticsHook struct {
+	diagnostics []Diagnostic
+}
+
+func (h *diagnosticsHook) Levels() []log.Level {
+	return []log.Level{log.PanicLevel, log.FatalLevel, log.ErrorLevel, log.WarnLevel}
+}
+
+func (h *diagnosticsHook) Fire(entry *log.Entry) error {
+	fields := make(map[string]interface{}, len(entry.Data))
+	for key, value := range entry.Data {
+		// errors don't survive encoding, e.g. to JSON
+		if valueErr, ok := value.(error); ok {
+			value = valueErr.Error()
+		}
+		fields[key] = value
+	}
+
+	h.diagnostics = append(h.diagnostics, Diagnostic{
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Fields:  fields,
+	})
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package generate
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	log "github.com/sirupsen/logrus"
+)
+
+// CheckSwagger generates code for a swagger file in memory and compares it with the files on disk
+// It returns a unified diff of the files that are missing or out of date, or an empty string when the
+// generated code is up to date; nothing is written
+func CheckSwagger(swaggerPath string) (diff string, err error) {
+	// the folder of the swagger file is the start of the go.mod search and the base of file references
+	if swaggerPath, err = filepath.Abs(swaggerPath); err != nil {
+		logger.WithField("swaggerFile", swaggerPath).Error(err)
+		return
+	}
+
+	var files map[string][]byte
+	if files, err = generateFromFile(swaggerPath); err != nil {
+		return
+	}
+
+	if diff, err = diffFiles(filepath.Dir(swaggerPath), files); err != nil {
+		return
+	}
+
+	if diff == "" {
+		log.Info("Generated code is up to date")
+	}
+
+	return
+}
+
+// diffFiles compares the generated files with the files on disk; paths in the diff are relative to dir
+func diffFiles(dir string, files map[string][]byte) (diff string, err error) {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buffer bytes.Buffer
+	for _, path := range paths {
+		var current []byte
+		if current, err = ioutil.ReadFile(path); err != nil && !os.IsNotExist(err) {
+			logger.WithField("file", path).Error(err)
+			return
+		}
+		err = nil
+
+		if bytes.Equal(current, files[path]) {
+			continue
+		}
+
+		name := path
+		if rel, relErr := filepath.Rel(dir, path); relErr == nil {
+			name = filepath.ToSlash(rel)
+		}
+
+		// a missing file is diffed against an empty one
+		fromFile := "a/" + name
+		if current == nil {
+			fromFile = "/dev/null"
+		}
+
+		if err = difflib.WriteUnifiedDiff(&buffer, difflib.UnifiedDiff{
+			A:        splitLines(string(current)),
+			B:        splitLines(string(files[path])),
+			FromFile: fromFile,
+			ToFile:   "b/" + name,
+			Context:  3,
+		}); err != nil {
+			logger.WithField("file", path).Error(err)
+			return
+		}
+	}
+
+	diff = buffer.String()
+
+	return
+}
+
+// splitLines splits text into lines that keep their "\n"
+// Unlike difflib.SplitLines, it doesn't add an empty line at the end, which shows up as a change in the diff
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -20,21 +20,60 @@ func restoreLogger(previousLogger log.FieldLogger) {
 }
 
 func getRefName(ref spec.Ref) (name string, err error) {
-	url := ref.GetURL()
-	if url == nil {
-		err = errors.New("Ref doesn't have a url")
-		logger.Error(err)
+	if name, err = getDefinitionName(ref); err != nil {
 		return
 	}
 
-	parts := strings.Split(url.Fragment, "/")
-	if len(parts) != 3 || parts[0] != "" || parts[1] != "definitions" {
-		err = errors.New("Only relative definitions are supported")
-		logger.WithField("fragment", url.Fragment).Error(err)
+	name = goFormat(name)
+	return
+}
+
+func getExtension(extensions spec.Extensions, key string) (value interface{}, ok bool) {
+	for k, v := range extensions {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+
+	return
+}
+
+// Numbers in vendor extensions are parsed as float64; make sure we have an integer
+func getIntExtension(extensions spec.Extensions, key string) (value int64, ok bool, err error) {
+	var raw interface{}
+	if raw, ok = getExtension(extensions, key); !ok {
+		return
+	}
+
+	number, isNumber := raw.(float64)
+	if !isNumber || float64(int64(number)) != number {
+		err = errors.New("Extension must be an integer")
+		logger.WithFields(log.Fields{
+			"extension": key,
+			"value":     raw,
+		}).Error(err)
+		return
+	}
+
+	value = int64(number)
+	return
+}
+
+// Booleans in vendor extensions; a missing extension is false
+func getBoolExtension(extensions spec.Extensions, key string) (value bool, err error) {
+	raw, ok := getExtension(extensions, key)
+	if !ok {
 		return
 	}
 
-	name = goFormat(parts[2])
+	if value, ok = raw.(bool); !ok {
+		err = errors.New("Extension must be a boolean")
+		logger.WithFields(log.Fields{
+			"extension": key,
+			"value":     raw,
+		}).Error(err)
+	}
+
 	return
 }
 
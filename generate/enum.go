@@ -0,0 +1,152 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-openapi/spec"
+	log "github.com/sirupsen/logrus"
+)
+
+// Enums of strings and integers are generated as a named Go type with a constant for each value
+type enumData struct {
+	// Go type of the values: string or an integer type
+	Type string
+	// Go expression that parses the variable text ([]byte) into an integer, for UnmarshalText; empty for strings
+	Parse  string
+	Values []enumValue
+}
+
+type enumValue struct {
+	// name of the constant
+	Name string
+	// Go literal of the value
+	Value string
+}
+
+// createEnumData returns the constants of an enum type, or nil if the validation has no enum
+// The constants are named after the type followed by the value, or by the name in x-enum-varnames
+func createEnumData(typeName, goType string, val validation, extensions spec.Extensions) (enum *enumData, err error) {
+	defer restoreLogger(logger)
+
+	_, isInteger := intRanges[goType]
+
+	var values []interface{}
+	switch {
+	case goType == "string" && val.String != nil && val.String.Enum != nil && val.Custom == nil:
+		for _, value := range val.String.Enum {
+			values = append(values, value)
+		}
+	case isInteger && val.Int != nil && val.Int.Enum != nil:
+		for _, value := range val.Int.Enum {
+			values = append(values, value)
+		}
+	default:
+		if _, ok := getExtension(extensions, "x-enum-varnames"); ok {
+			err = errors.New("x-enum-varnames is only supported for string and integer enums")
+			logger.Error(err)
+		}
+		return
+	}
+
+	logger = logger.WithField("enumType", typeName)
+
+	var varNames []string
+	if varNames, err = getEnumVarNames(extensions, len(values)); err != nil {
+		return
+	}
+
+	enum = &enumData{Type: goType}
+	if goType != "string" {
+		parseFunc := "ParseInt"
+		if strings.HasPrefix(goType, "uint") {
+			parseFunc = "ParseUint"
+		}
+		// the bit size is at the end of the type name
+		enum.Parse = fmt.Sprintf("strconv.%s(string(text), 10, %s)", parseFunc, goType[len(goType)-2:])
+	}
+
+	names := make(map[string]bool)
+	for i, value := range values {
+		v := enumValue{}
+
+		switch value := value.(type) {
+		case string:
+			v.Name = typeName + enumConstName(value)
+			v.Value = strconv.Quote(value)
+		case int64:
+			v.Name = typeName + enumConstName(strconv.FormatInt(value, 10))
+			v.Value = strconv.FormatInt(value, 10)
+		}
+
+		if varNames != nil {
+			v.Name = typeName + goFormat(varNames[i])
+		}
+
+		if names[v.Name] {
+			err = errors.New("Enum values lead to duplicate constant names; use x-enum-varnames to name them")
+			logger.WithFields(log.Fields{
+				"value":    value,
+				"constant": v.Name,
+			}).Error(err)
+			return
+		}
+		names[v.Name] = true
+
+		enum.Values = append(enum.Values, v)
+	}
+
+	return
+}
+
+// x-enum-varnames lists the names of the constants, in the same order as the enum values
+func getEnumVarNames(extensions spec.Extensions, count int) (varNames []string, err error) {
+	extension, ok := getExtension(extensions, "x-enum-varnames")
+	if !ok {
+		return
+	}
+
+	list, isList := extension.([]interface{})
+	if !isList || len(list) != count {
+		err = errors.New("x-enum-varnames must be a list with a name for each enum value")
+		logger.WithField("varNames", extension).Error(err)
+		return
+	}
+
+	for _, item := range list {
+		name, isString := item.(string)
+		if !isString || goFormat(name) == "" {
+			err = errors.New("x-enum-varnames must contain non-empty strings")
+			logger.WithField("varName", item).Error(err)
+			return
+		}
+		varNames = append(varNames, name)
+	}
+
+	return
+}
+
+// enumConstName turns an enum value into the part of a constant name after the type name
+// e.g. "in-progress" --> InProgress, "-1" --> Minus1
+func enumConstName(value string) string {
+	prefix := ""
+	if strings.HasPrefix(value, "-") {
+		prefix = "Minus"
+	}
+
+	words := strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "Empty"
+	}
+
+	for i := range words {
+		words[i] = strings.Title(words[i])
+	}
+
+	return prefix + handleCommonInitialisms(strings.Join(words, ""), false)
+}
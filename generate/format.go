@@ -0,0 +1,108 @@
+package generate
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"sort"
+)
+
+// Format is a custom string format, for format values that are not supported out of the box
+type Format struct {
+	// Go type of values with this format, e.g. iban.IBAN
+	Type string `json:"type"`
+	// Import path of the package that contains Type, Validate and Parse; empty if no import is needed
+	Import string `json:"import"`
+	// Function that validates a value, with signature func(Type) error
+	Validate string `json:"validate"`
+	// Function that parses a path, query or header parameter, with signature func(string) (Type, error)
+	// When empty, parameters are converted to Type, so Type needs to be a string type
+	Parse string `json:"parse"`
+}
+
+// Config is the content of a generator config file
+type Config struct {
+	// Custom string formats by name
+	Formats map[string]Format `json:"formats"`
+	// Where the generated files are written
+	Layout Layout `json:"layout"`
+}
+
+// custom string formats by name
+var customFormats = map[string]Format{}
+
+// RegisterFormat registers a custom string format
+// Properties and parameters with this format get the Go type of the format and are validated with its validation function
+func RegisterFormat(name string, format Format) (err error) {
+	defer restoreLogger(logger)
+	logger = logger.WithField("format", name)
+
+	if _, ok := stringFormats[name]; ok {
+		err = errors.New("Cannot override a built-in format")
+		logger.Error(err)
+		return
+	}
+
+	if format.Type == "" || format.Validate == "" {
+		err = errors.New("Custom formats need a type and a validation function")
+		logger.Error(err)
+		return
+	}
+
+	customFormats[name] = format
+
+	return
+}
+
+// LoadConfig reads a JSON config file, registers the custom formats in it and sets the layout
+func LoadConfig(path string) (err error) {
+	defer restoreLogger(logger)
+	logger = logger.WithField("config", path)
+
+	var data []byte
+	if data, err = ioutil.ReadFile(path); err != nil {
+		logger.Error(err)
+		return
+	}
+
+	var config Config
+	if err = json.Unmarshal(data, &config); err != nil {
+		logger.WithField("error", err).Error("Invalid config file")
+		return
+	}
+
+	for name, format := range config.Formats {
+		if err = RegisterFormat(name, format); err != nil {
+			return
+		}
+	}
+
+	if err = SetLayout(config.Layout); err != nil {
+		return
+	}
+
+	logger.WithField("formats", len(config.Formats)).Info("Loaded config")
+
+	return
+}
+
+// import paths of strfmt and the custom formats, so the templates can add them to the generated files
+// Packages outside the standard library cannot always be found when formatting, so they are imported
+// explicitly; formatting removes the ones that are not used
+func customFormatImports() (imports []string) {
+	unique := map[string]struct{}{
+		"github.com/go-openapi/strfmt": struct{}{},
+	}
+	for _, format := range customFormats {
+		if format.Import != "" {
+			unique[format.Import] = struct{}{}
+		}
+	}
+
+	for i := range unique {
+		imports = append(imports, i)
+	}
+	sort.Strings(imports)
+
+	return
+}
@@ -0,0 +1,174 @@
+package generate
+
+import (
+	"errors"
+	"go/token"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Layout describes where the generated files are written and which Go packages they are in
+type Layout struct {
+	// Directory of the generated code; a relative path is relative to the folder of the swagger file
+	Dir string `json:"dir"`
+	// Go file that contains the swagger spec; a relative path is relative to Dir
+	Swagger string `json:"swagger"`
+	// Directory of the model package, which also contains the errors; a relative path is relative to Dir
+	Model string `json:"model"`
+	// Directory of the router package; a relative path is relative to Dir
+	Router string `json:"router"`
+	// Directory of the shared model package, with the types of definitions in other files than the swagger file;
+	// a relative path is relative to Dir. When empty, those types are generated in the model package
+	Shared string `json:"shared"`
+	// Directory of the scaffold, a main package with a starting point for the implementation of the service;
+	// a relative path is relative to Dir. Its files are only written when they don't exist yet
+	Scaffold string `json:"scaffold"`
+
+	// Go package names; when empty, the name of the directory is used
+	SwaggerPackage string `json:"swaggerPackage"`
+	ModelPackage   string `json:"modelPackage"`
+	RouterPackage  string `json:"routerPackage"`
+	SharedPackage  string `json:"sharedPackage"`
+}
+
+var defaultLayout = Layout{
+	Dir:     "generated",
+	Swagger: "swagger.go",
+	Model:   "model",
+	Router:  "router",
+}
+
+// the layout that is used for generating; starts with the defaults
+var layout = defaultLayout
+
+// SetLayout changes where the generated files are written
+// Empty fields keep their current value, so a config file can be combined with command line flags
+func SetLayout(l Layout) (err error) {
+	newLayout := layout
+
+	setIfPresent := func(field *string, value string) {
+		if value != "" {
+			*field = value
+		}
+	}
+
+	setIfPresent(&newLayout.Dir, l.Dir)
+	setIfPresent(&newLayout.Swagger, l.Swagger)
+	setIfPresent(&newLayout.Model, l.Model)
+	setIfPresent(&newLayout.Router, l.Router)
+	setIfPresent(&newLayout.SwaggerPackage, l.SwaggerPackage)
+	setIfPresent(&newLayout.ModelPackage, l.ModelPackage)
+	setIfPresent(&newLayout.RouterPackage, l.RouterPackage)
+	setIfPresent(&newLayout.Shared, l.Shared)
+	setIfPresent(&newLayout.SharedPackage, l.SharedPackage)
+	setIfPresent(&newLayout.Scaffold, l.Scaffold)
+
+	for _, name := range []string{newLayout.SwaggerPackage, newLayout.ModelPackage, newLayout.RouterPackage, newLayout.SharedPackage} {
+		if name != "" && !token.IsIdentifier(name) {
+			err = errors.New("Package name must be a valid Go identifier")
+			logger.WithField("package", name).Error(err)
+			return
+		}
+	}
+
+	layout = newLayout
+
+	return
+}
+
+// the output files of one swagger file, with absolute paths
+type outputLayout struct {
+	SwaggerFile    string
+	ModelDir       string
+	RouterDir      string
+	SwaggerPackage string
+	ModelPackage   string
+	RouterPackage  string
+	// empty if there is no shared model package
+	SharedDir     string
+	SharedPackage string
+	// empty if there is no scaffold
+	ScaffoldDir string
+}
+
+func getOutputLayout(swaggerDir string) (output outputLayout, err error) {
+	defer restoreLogger(logger)
+
+	dir := resolvePath(swaggerDir, layout.Dir)
+
+	output = outputLayout{
+		SwaggerFile: resolvePath(dir, layout.Swagger),
+		ModelDir:    resolvePath(dir, layout.Model),
+		RouterDir:   resolvePath(dir, layout.Router),
+	}
+	if layout.Shared != "" {
+		output.SharedDir = resolvePath(dir, layout.Shared)
+	}
+	if layout.Scaffold != "" {
+		output.ScaffoldDir = resolvePath(dir, layout.Scaffold)
+	}
+
+	logger = logger.WithFields(log.Fields{
+		"swaggerFile": output.SwaggerFile,
+		"modelDir":    output.ModelDir,
+		"routerDir":   output.RouterDir,
+		"sharedDir":   output.SharedDir,
+		"scaffoldDir": output.ScaffoldDir,
+	})
+
+	// the model, router and shared model are different packages, and the swagger file must not end up in any of them
+	swaggerFileDir := filepath.Dir(output.SwaggerFile)
+	if output.ModelDir == output.RouterDir || swaggerFileDir == output.ModelDir || swaggerFileDir == output.RouterDir {
+		err = errors.New("The swagger file, model and router must be in different directories")
+		logger.Error(err)
+		return
+	}
+	if output.SharedDir != "" && (output.SharedDir == output.ModelDir || output.SharedDir == output.RouterDir || output.SharedDir == swaggerFileDir) {
+		err = errors.New("The shared model must be in a different directory than the other generated files")
+		logger.Error(err)
+		return
+	}
+	if output.ScaffoldDir != "" && (output.ScaffoldDir == output.ModelDir || output.ScaffoldDir == output.RouterDir || output.ScaffoldDir == swaggerFileDir || output.ScaffoldDir == output.SharedDir) {
+		err = errors.New("The scaffold must be in a different directory than the other generated files")
+		logger.Error(err)
+		return
+	}
+
+	if output.SwaggerPackage, err = getPackageName(layout.SwaggerPackage, swaggerFileDir); err != nil {
+		return
+	}
+	if output.ModelPackage, err = getPackageName(layout.ModelPackage, output.ModelDir); err != nil {
+		return
+	}
+	if output.RouterPackage, err = getPackageName(layout.RouterPackage, output.RouterDir); err != nil {
+		return
+	}
+	if output.SharedDir != "" {
+		output.SharedPackage, err = getPackageName(layout.SharedPackage, output.SharedDir)
+	}
+
+	return
+}
+
+func resolvePath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+// by default, the package is named after its directory
+func getPackageName(name, dir string) (packageName string, err error) {
+	if name != "" {
+		return name, nil
+	}
+
+	packageName = filepath.Base(dir)
+	if !token.IsIdentifier(packageName) {
+		err = errors.New("Directory name is not a valid package name; set the package name explicitly")
+		logger.WithField("directory", dir).Error(err)
+	}
+
+	return
+}
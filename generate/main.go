@@ -1,36 +1,36 @@
 package generate
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 	"io/ioutil"
-	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
 
 	"github.com/fujitsueos/go-server-generator/templates"
 	"github.com/go-openapi/loads"
 	"github.com/go-openapi/spec"
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 	"github.com/go-openapi/validate"
 	log "github.com/sirupsen/logrus"
 )
 
-func init() {
-	if err := exec.Command("goimports").Run(); err != nil {
-		log.Fatal("Could not run goimports, make sure it's installed.\nInstall it by running:\ngo get golang.org/x/tools/cmd/goimports")
-	}
-}
-
-// FromSwagger generates a "generated" folder with generated code in the same folder as the swagger file
+// FromSwagger generates code for a swagger file, by default in a "generated" folder next to the swagger file
+// Use SetLayout or a config file to change where the files are written
 func FromSwagger(swaggerPath string) (err error) {
-	var swagger *spec.Swagger
+	// the folder of the swagger file is the start of the go.mod search and the base of file references
+	if swaggerPath, err = filepath.Abs(swaggerPath); err != nil {
+		logger.WithField("swaggerFile", swaggerPath).Error(err)
+		return
+	}
 
-	if swagger, err = readValidSwagger(swaggerPath); err != nil {
+	var files map[string][]byte
+	if files, err = generateFromFile(swaggerPath); err != nil {
 		return
 	}
 
-	if err = generateServer(swaggerPath, swagger); err != nil {
+	if err = writeFiles(files); err != nil {
 		return
 	}
 
@@ -39,104 +39,262 @@ func FromSwagger(swaggerPath string) (err error) {
 	return
 }
 
-func readValidSwagger(swaggerPath string) (swagger *spec.Swagger, err error) {
+// generateFromFile renders all generated files of a swagger file in memory
+func generateFromFile(swaggerPath string) (files map[string][]byte, err error) {
+	var swaggerData []byte
+	if swaggerData, err = ioutil.ReadFile(swaggerPath); err != nil {
+		logger.WithField("swaggerFile", swaggerPath).Error(err)
+		return
+	}
+
+	var (
+		swagger             *spec.Swagger
+		externalDefinitions map[string]string
+	)
+	if swagger, externalDefinitions, err = readValidSwagger(swaggerPath); err != nil {
+		return
+	}
+
+	files, err = generateServer(filepath.Dir(swaggerPath), swaggerData, swagger, externalDefinitions)
+
+	return
+}
+
+func readValidSwagger(swaggerPath string) (swagger *spec.Swagger, externalDefinitions map[string]string, err error) {
 	var specDoc *loads.Document
 
 	if specDoc, err = loads.Spec(swaggerPath); err != nil {
 		return
 	}
 
-	if err = validate.Spec(specDoc, strfmt.Default); err != nil {
+	swagger, externalDefinitions, err = validSwagger(specDoc, swaggerPath)
+
+	return
+}
+
+// parseValidSwagger reads a swagger spec in JSON or YAML
+// References to other files are relative to rootPath
+func parseValidSwagger(swaggerData []byte, rootPath string) (swagger *spec.Swagger, externalDefinitions map[string]string, err error) {
+	var yamlDoc interface{}
+	if yamlDoc, err = swag.BytesToYAMLDoc(swaggerData); err != nil {
+		logger.Error(err)
 		return
 	}
 
-	swagger = specDoc.Spec()
+	var jsonDoc json.RawMessage
+	if jsonDoc, err = swag.YAMLToJSON(yamlDoc); err != nil {
+		logger.Error(err)
+		return
+	}
+
+	var specDoc *loads.Document
+	if specDoc, err = loads.Analyzed(jsonDoc, ""); err != nil {
+		logger.Error(err)
+		return
+	}
+
+	swagger, externalDefinitions, err = validSwagger(specDoc, rootPath)
 
 	return
 }
 
-func generateServer(path string, swagger *spec.Swagger) (err error) {
-	paths := []string{
-		"generated/swagger.go",
-		"generated/model/model.go",
-		"generated/model/validate.go",
-		"generated/model/errors.go",
-		"generated/model/routeerrors.go",
-		"generated/router/router.go",
+// validSwagger imports the definitions of other files and validates the spec
+// externalDefinitions contains the file of every imported definition, by name
+func validSwagger(specDoc *loads.Document, rootPath string) (swagger *spec.Swagger, externalDefinitions map[string]string, err error) {
+	if externalDefinitions, err = importExternalDefinitions(specDoc.Spec(), rootPath); err != nil {
+		return
 	}
 
-	files := map[string]*os.File{}
-	packages := map[string]string{}
-
-	for _, p := range paths {
-		name := strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))
-		var closeFile func()
-		if files[name], packages[name], closeFile, err = createOutputFile(path, p); err != nil {
+	if len(externalDefinitions) > 0 {
+		if specDoc, err = reanalyze(specDoc); err != nil {
 			return
 		}
-		defer closeFile()
 	}
 
-	// turn swagger file into a Go string
-	if err = inlineSwaggerFile(path, files["swagger"]); err != nil {
+	if specDoc, err = convertWriteOnly(specDoc); err != nil {
 		return
 	}
 
-	// create the model and write to the model and validate files
-	var readOnlyTypes map[string]bool
-	if readOnlyTypes, err = Model(files["model"], files["validate"], files["errors"], swagger.Definitions); err != nil {
+	if err = validate.Spec(specDoc, strfmt.Default); err != nil {
 		return
 	}
 
-	// create the router and write to the router file
-	err = Router(files["router"], files["routeerrors"], swagger.Paths, readOnlyTypes, packages["model"])
+	swagger = specDoc.Spec()
 
 	return
 }
 
-func createOutputFile(swaggerPath, relativePath string) (file *os.File, packageName string, closeFile func(), err error) {
-	path := filepath.Join(filepath.Dir(swaggerPath), relativePath)
-	folder := filepath.Dir(path)
+// writeOnly is an OpenAPI 3 keyword that swagger 2 doesn't allow on properties, so it is turned into
+// x-writeOnly before validating the spec
+func convertWriteOnly(specDoc *loads.Document) (converted *loads.Document, err error) {
+	converted = specDoc
 
-	// create the folder and file
-	if err = os.MkdirAll(folder, os.ModePerm); err != nil {
+	found := false
+	for _, definition := range specDoc.Spec().Definitions {
+		for name, property := range definition.Properties {
+			writeOnly, ok := property.ExtraProps["writeOnly"]
+			if !ok {
+				continue
+			}
+
+			delete(property.ExtraProps, "writeOnly")
+			property.AddExtension("x-writeOnly", writeOnly)
+			definition.Properties[name] = property
+			found = true
+		}
+	}
+
+	if !found {
 		return
 	}
-	if file, err = os.Create(path); err != nil {
+
+	converted, err = reanalyze(specDoc)
+
+	return
+}
+
+// reanalyze analyzes a spec again after changing it
+func reanalyze(specDoc *loads.Document) (analyzed *loads.Document, err error) {
+	var data []byte
+	if data, err = json.Marshal(specDoc.Spec()); err != nil {
+		logger.Error(err)
 		return
 	}
 
-	// get the name of the go package
-	goSrcPath := filepath.Join(os.Getenv("GOPATH"), "src")
-	if packageName, err = filepath.Rel(goSrcPath, folder); err != nil {
+	analyzed, err = loads.Analyzed(data, "")
+
+	return
+}
+
+// generateServer renders all generated files in memory, by absolute path
+// The generated files are placed relative to swaggerDir, the folder of the swagger file
+// externalDefinitions are the definitions that were imported from other files
+func generateServer(swaggerDir string, swaggerData []byte, swagger *spec.Swagger, externalDefinitions map[string]string) (files map[string][]byte, err error) {
+	var output outputLayout
+	if output, err = getOutputLayout(swaggerDir); err != nil {
+		return
+	}
+
+	paths := map[string]string{
+		"swagger":     output.SwaggerFile,
+		"model":       filepath.Join(output.ModelDir, "model.go"),
+		"validate":    filepath.Join(output.ModelDir, "validate.go"),
+		"errors":      filepath.Join(output.ModelDir, "errors.go"),
+		"routeerrors": filepath.Join(output.ModelDir, "routeerrors.go"),
+		"router":      filepath.Join(output.RouterDir, "router.go"),
+	}
+
+	// the router imports the model
+	var modelImport string
+	if modelImport, err = getImportPath(swaggerDir, output.ModelDir); err != nil {
 		return
 	}
 
-	closeFile = func() {
-		log.Infof("Format file %s", path)
-		if err := exec.Command("goimports", "-w", path).Run(); err != nil {
-			log.Errorf("Could not format file %s", path)
+	// the model imports the shared model, if there is one
+	var sharedImport string
+	if output.SharedDir != "" {
+		paths["sharedModel"] = filepath.Join(output.SharedDir, "model.go")
+		paths["sharedValidate"] = filepath.Join(output.SharedDir, "validate.go")
+
+		if sharedImport, err = getImportPath(swaggerDir, output.SharedDir); err != nil {
+			return
 		}
-		if err := file.Close(); err != nil {
-			log.Error(err)
+	}
+
+	// the scaffold belongs to the service once it exists, so only the missing files are created
+	if output.ScaffoldDir != "" {
+		for name, file := range scaffoldFiles {
+			p := filepath.Join(output.ScaffoldDir, file)
+
+			var exists bool
+			if exists, err = fileExists(p); err != nil {
+				return
+			}
+			if !exists {
+				paths[name] = p
+			}
 		}
 	}
 
-	return
-}
+	// the generated code contains a spec without references to other files
+	if len(externalDefinitions) > 0 {
+		if swaggerData, err = json.MarshalIndent(swagger, "", "  "); err != nil {
+			logger.Error(err)
+			return
+		}
+	}
 
-func inlineSwaggerFile(swaggerPath string, file io.Writer) (err error) {
-	var swaggerFile io.Reader
-	if swaggerFile, err = os.Open(swaggerPath); err != nil {
+	// render everything in memory first, so a template error doesn't leave half-written files
+	buffers := map[string]*bytes.Buffer{}
+	for name := range paths {
+		buffers[name] = &bytes.Buffer{}
+	}
+
+	// turn swagger file into a Go string
+	if err = inlineSwaggerFile(swaggerData, buffers["swagger"], output.SwaggerPackage); err != nil {
 		return
 	}
 
-	var swaggerData []byte
-	if swaggerData, err = ioutil.ReadAll(swaggerFile); err != nil {
+	var shared *SharedModel
+	if output.SharedDir != "" {
+		shared = &SharedModel{
+			Definitions:    make(map[string]bool, len(externalDefinitions)),
+			Package:        output.SharedPackage,
+			Import:         sharedImport,
+			ModelWriter:    buffers["sharedModel"],
+			ValidateWriter: buffers["sharedValidate"],
+		}
+		for name := range externalDefinitions {
+			shared.Definitions[name] = true
+		}
+	}
+
+	// create the model and write to the model and validate files
+	var readOnlyTypes, additionalPropsTypes map[string]bool
+	if readOnlyTypes, additionalPropsTypes, err = Model(buffers["model"], buffers["validate"], buffers["errors"], swagger.Definitions, output.ModelPackage, shared); err != nil {
+		return
+	}
+
+	// create the router and write to the router file
+	if err = Router(buffers["router"], buffers["routeerrors"], swagger, readOnlyTypes, additionalPropsTypes, output.RouterPackage, modelImport, output.ModelPackage); err != nil {
 		return
 	}
 
-	err = templates.Swagger.Execute(file, string(swaggerData))
+	if output.ScaffoldDir != "" {
+		// the main package of the scaffold imports the router as well
+		var routerImport string
+		if routerImport, err = getImportPath(swaggerDir, output.RouterDir); err != nil {
+			return
+		}
+
+		// files that already exist are rendered, but not returned
+		writers := map[string]io.Writer{}
+		for name := range scaffoldFiles {
+			writers[name] = ioutil.Discard
+			if buffer, ok := buffers[name]; ok {
+				writers[name] = buffer
+			}
+		}
+
+		if err = Scaffold(writers["scaffoldHandler"], writers["scaffoldErrorTransformer"], writers["scaffoldMain"], swagger, readOnlyTypes, additionalPropsTypes, routerImport, modelImport); err != nil {
+			return
+		}
+	}
+
+	// format and check all files before writing any of them
+	files = make(map[string][]byte, len(paths))
+	for name, p := range paths {
+		if files[p], err = formatSource(p, buffers[name].Bytes()); err != nil {
+			return
+		}
+		if err = checkSource(p, files[p]); err != nil {
+			return
+		}
+	}
 
 	return
 }
+
+func inlineSwaggerFile(swaggerData []byte, file io.Writer, packageName string) error {
+	return templates.Swagger.Execute(file, struct{ Package, Swagger string }{packageName, string(swaggerData)})
+}
@@ -12,22 +12,36 @@ import (
 )
 
 type modelData struct {
-	Types    []typeData
-	Patterns []patternData
+	Package     string
+	Types       []typeData
+	Patterns    []patternData
+	Imports     []string
+	HasNullable bool
+
+	// the types of the shared model package, which the model refers to with aliases
+	SharedTypes   []typeData
+	SharedPackage string
+	SharedImport  string
+	// true for the shared model package, whose types are validated from other packages as well
+	IsShared bool
 }
 
 type typeData struct {
 	// general fields
-	Name             string
-	PrivateName      string
-	Description      string
-	Validation       validation
+	Name        string
+	PrivateName string
+	Description string
+	Validation  validation
+	// true if the type has read-only or write-only properties, or references a type that has them;
+	// such types get a ReadOnly variant that is used in responses
 	HasReadOnlyProps bool
 	IsError          bool
 
 	// struct fields
 	IsStruct bool
 	Props    []propsData
+	// nil if the spec doesn't say whether additional properties are allowed
+	AdditionalProps *bool
 
 	// slice fields
 	IsSlice        bool
@@ -37,6 +51,14 @@ type typeData struct {
 	// primitive type and reference fields
 	Type string
 	Ref  *typeData
+
+	// nil unless the type is a string or integer enum
+	Enum *enumData
+
+	// the type is generated in the shared model package
+	IsShared bool
+	// the items or the referenced type are in the shared model package
+	HasSharedType bool
 }
 
 type propsData struct {
@@ -45,10 +67,23 @@ type propsData struct {
 	Description string
 	Validation  validation
 	IsReadOnly  bool
+	// x-writeOnly properties are only in requests; the ReadOnly variant of the object hides them
+	IsWriteOnly bool
+	// the type of the property or its items has a ReadOnly variant, which the ReadOnly variant of the object uses
+	HasReadOnlyType bool
+	// the type of the property or its items is in the shared model package
+	HasSharedType bool
 
 	// actually a validation field, but this is easier for the template
 	IsRequired bool
 
+	// x-nullable: true wraps the value in Optional, to tell null apart from a missing property
+	// x-nullable: false stores the value itself instead of a pointer
+	Nullable   bool
+	NonPointer bool
+	// x-omitempty leaves missing properties out of the JSON instead of writing null
+	OmitEmpty bool
+
 	// slice fields
 	IsSlice        bool
 	ItemType       string
@@ -59,6 +94,7 @@ type propsData struct {
 }
 
 type errorsData struct {
+	Package    string
 	Types      []typeData
 	BaseErrors []string
 }
@@ -68,16 +104,68 @@ type patternData struct {
 	Pattern string
 }
 
+// SharedModel is a separate package for the types of definitions in other files than the swagger file,
+// so several services can use the same types
+type SharedModel struct {
+	// Definitions that are generated in the shared package; error definitions are always in the model
+	Definitions map[string]bool
+	// Package name and import path of the shared package
+	Package string
+	Import  string
+	// Files of the shared package
+	ModelWriter, ValidateWriter io.Writer
+}
+
 // Model generates the model based on a definitions spec
-func Model(modelWriter, validateWriter, errorsWriter io.Writer, definitions spec.Definitions) (readOnlyTypes map[string]bool, err error) {
+// packageName is the name of the Go package of the model
+// shared is nil when all types are generated in the model package
+// additionalPropsTypes contains the types that allow (true) or forbid (false) additional properties, themselves or in a
+// type they reference
+func Model(modelWriter, validateWriter, errorsWriter io.Writer, definitions spec.Definitions, packageName string, shared *SharedModel) (readOnlyTypes, additionalPropsTypes map[string]bool, err error) {
 	var (
 		model  modelData
 		errors errorsData
 	)
-	if model, readOnlyTypes, errors, err = createModel(definitions); err != nil {
+
+	var sharedDefinitions map[string]bool
+	if shared != nil {
+		sharedDefinitions = shared.Definitions
+	}
+
+	if model, readOnlyTypes, errors, err = createModel(definitions, sharedDefinitions); err != nil {
 		return
 	}
 
+	model.Package = packageName
+	errors.Package = packageName
+
+	if additionalPropsTypes, err = getAdditionalPropsTypes(model.Types); err != nil {
+		return
+	}
+
+	if shared != nil {
+		var sharedModel modelData
+		if model, sharedModel, err = splitSharedModel(model, errors.Types); err != nil {
+			return
+		}
+
+		sharedModel.Package = shared.Package
+		completeModel(&sharedModel)
+
+		if err = templates.Model.Execute(shared.ModelWriter, sharedModel); err != nil {
+			return
+		}
+
+		if err = templates.Validate.Execute(shared.ValidateWriter, sharedModel); err != nil {
+			return
+		}
+
+		model.SharedPackage = shared.Package
+		model.SharedImport = shared.Import
+	}
+
+	completeModel(&model)
+
 	if err = templates.Model.Execute(modelWriter, model); err != nil {
 		return
 	}
@@ -91,9 +179,11 @@ func Model(modelWriter, validateWriter, errorsWriter io.Writer, definitions spec
 	return
 }
 
-func createModel(definitions spec.Definitions) (model modelData, readOnlyTypes map[string]bool, errors errorsData, err error) {
+// sharedDefinitions are the definitions whose types are generated in the shared model package
+func createModel(definitions spec.Definitions, sharedDefinitions map[string]bool) (model modelData, readOnlyTypes map[string]bool, errors errorsData, err error) {
 	originalLogger := logger
 
+	var allEnumTypes []typeData
 	for name, definition := range definitions {
 		logger = originalLogger.WithFields(log.Fields{
 			"definition": name,
@@ -101,23 +191,36 @@ func createModel(definitions spec.Definitions) (model modelData, readOnlyTypes m
 
 		logger.Info("Generating model")
 
-		var t typeData
-		if t, err = createTypeData(name, definition.Description, definition); err != nil {
+		var (
+			t         typeData
+			enumTypes []typeData
+		)
+		if t, enumTypes, err = createTypeData(name, definition.Description, definition); err != nil {
 			return
 		}
 
 		if t.IsError {
 			errors.Types = append(errors.Types, t)
 		} else {
+			if sharedDefinitions[name] {
+				t.IsShared = true
+				for i := range enumTypes {
+					enumTypes[i].IsShared = true
+				}
+			}
 			model.Types = append(model.Types, t)
 		}
+		allEnumTypes = append(allEnumTypes, enumTypes...)
 	}
 
 	logger = originalLogger
 
-	if readOnlyTypes, err = checkReadOnlyTypes(&model); err != nil {
+	if err = checkEnumTypeNames(model.Types, errors.Types, allEnumTypes); err != nil {
 		return
 	}
+	model.Types = append(model.Types, allEnumTypes...)
+
+	readOnlyTypes = getReadOnlyTypes(model.Types)
 
 	linkReferences(model.Types, errors.Types)
 	errors.BaseErrors = getReferences(errors.Types)
@@ -125,12 +228,83 @@ func createModel(definitions spec.Definitions) (model modelData, readOnlyTypes m
 	sortTypes(model.Types)
 	sortTypes(errors.Types)
 
-	listPatterns(&model)
+	return
+}
+
+// completeModel adds what the model file needs besides the types
+func completeModel(model *modelData) {
+	listPatterns(model)
+
+	model.Imports = customFormatImports()
+
+	for _, t := range model.Types {
+		for _, p := range t.Props {
+			model.HasNullable = model.HasNullable || p.Nullable
+		}
+	}
+}
+
+// splitSharedModel moves the shared types to a separate model
+// The model keeps them in SharedTypes, to refer to them with aliases
+func splitSharedModel(all modelData, errorTypes []typeData) (model, shared modelData, err error) {
+	defer restoreLogger(logger)
+
+	model.Package = all.Package
+	shared.IsShared = true
+
+	sharedTypes := make(map[string]bool)
+	for _, t := range all.Types {
+		if t.IsShared {
+			shared.Types = append(shared.Types, t)
+			sharedTypes[t.Name] = true
+		} else {
+			model.Types = append(model.Types, t)
+		}
+	}
+	model.SharedTypes = shared.Types
+
+	// the shared package cannot import the model, so shared types only reference each other
+	modelTypes := make(map[string]bool)
+	for _, ts := range [][]typeData{model.Types, errorTypes} {
+		for _, t := range ts {
+			modelTypes[t.Name] = true
+		}
+	}
+	for i := range shared.Types {
+		for _, dependency := range getDependencies(&shared.Types[i]) {
+			if modelTypes[dependency] {
+				err = errors.New("A type in the shared model cannot reference a type of the swagger file or an error")
+				logger.WithFields(log.Fields{
+					"type":       shared.Types[i].Name,
+					"dependency": dependency,
+				}).Error(err)
+				return
+			}
+		}
+	}
+
+	for i := range model.Types {
+		t := &model.Types[i]
+		t.HasSharedType = sharedTypes[t.Type] || sharedTypes[t.ItemType]
+
+		for j := range t.Props {
+			p := &t.Props[j]
+			p.HasSharedType = sharedTypes[p.Type] || sharedTypes[p.ItemType]
+		}
+	}
 
 	return
 }
 
-func createTypeData(name, description string, schema spec.Schema) (t typeData, err error) {
+// names of types that are always generated in the model package
+var reservedTypes = map[string]bool{
+	"ValidationError":  true,
+	"ValidationErrors": true,
+	"Optional":         true,
+}
+
+// enumTypes are the types of enum properties and array items, which are named after the type that contains them
+func createTypeData(name, description string, schema spec.Schema) (t typeData, enumTypes []typeData, err error) {
 	defer restoreLogger(logger)
 
 	var (
@@ -139,6 +313,12 @@ func createTypeData(name, description string, schema spec.Schema) (t typeData, e
 		isSlice      bool
 	)
 
+	if reservedTypes[goFormat(name)] {
+		err = errors.New("Type name is reserved for generated code")
+		logger.Error(err)
+		return
+	}
+
 	if goType, val, itemVal, isSlice, err = getType(schema); err != nil {
 		return
 	}
@@ -146,7 +326,7 @@ func createTypeData(name, description string, schema spec.Schema) (t typeData, e
 	isError, _ := schema.Extensions.GetBool("x-error")
 	if isError {
 		_, isPrimitive := goPrimitives[goType]
-		if isSlice || (isPrimitive && goType != "string") {
+		if isSlice || (isPrimitive && goType != "string") || val.Custom != nil {
 			err = errors.New("Errors can only be objects, strings, or references")
 			logger.Error(err)
 			return
@@ -166,37 +346,108 @@ func createTypeData(name, description string, schema spec.Schema) (t typeData, e
 	if goType == "struct" {
 		t.IsStruct = true
 
+		if schema.AdditionalProperties != nil {
+			allowed := schema.AdditionalProperties.Allows || schema.AdditionalProperties.Schema != nil
+			t.AdditionalProps = &allowed
+		}
+
 		required := []string{}
 		if val.Object != nil {
 			required = val.Object.Required
 		}
 
-		if t.Props, t.HasReadOnlyProps, err = createObjectProps(schema, required); err != nil {
+		var omitEmpty bool
+		if omitEmpty, err = getBoolExtension(schema.Extensions, "x-omitempty"); err != nil {
+			return
+		}
+
+		if t.Props, enumTypes, t.HasReadOnlyProps, err = createObjectProps(t.Name, schema, required, omitEmpty); err != nil {
 			return
 		}
 
 		if t.IsError && t.HasReadOnlyProps {
-			err = errors.New("Errors with read-only props are not suppored")
+			err = errors.New("Errors with read-only or write-only props are not suppored")
 			logger.Error(err)
 			return
 		}
-		if t.IsError && (t.Validation.hasValidation() || t.ItemValidation.hasValidation()) {
+		if t.IsError && (t.Validation.hasValidation() || t.ItemValidation.hasValidation() || hasPropValidation(t.Props) || len(enumTypes) > 0) {
 			err = errors.New("Errors with validation rules are not suppored")
 			logger.Error(err)
 			return
 		}
+		if t.IsError && hasNullableProps(t.Props) {
+			err = errors.New("Errors with x-nullable props are not suppored")
+			logger.Error(err)
+			return
+		}
 	} else if isSlice {
 		t.IsSlice = true
 		t.ItemType = goType
 		t.ItemValidation = itemVal
+
+		var enumType *typeData
+		if enumType, err = createEnumType(t.Name+"Item", goType, itemVal, *schema.Items.Schema); err != nil {
+			return
+		}
+		if enumType != nil {
+			enumTypes = append(enumTypes, *enumType)
+			t.ItemType = enumType.Name
+			t.ItemValidation = validation{}
+		}
 	} else {
 		t.Type = goType
+
+		if !t.IsError {
+			if t.Enum, err = createEnumData(t.Name, goType, val, schema.Extensions); err != nil {
+				return
+			}
+		}
 	}
 
 	return
 }
 
-func createObjectProps(definition spec.Schema, requiredProps []string) (props []propsData, hasReadOnlyProps bool, err error) {
+// createEnumType returns the type of an enum property or array item, or nil if schema has no enum
+func createEnumType(name, goType string, val validation, schema spec.Schema) (t *typeData, err error) {
+	var enum *enumData
+	if enum, err = createEnumData(name, goType, val, schema.Extensions); err != nil || enum == nil {
+		return
+	}
+
+	t = &typeData{
+		Name:        name,
+		PrivateName: lowerStart(name),
+		Description: schema.Description,
+		Validation:  val,
+		Type:        goType,
+		Enum:        enum,
+	}
+
+	return
+}
+
+// The types of enum properties and items must not collide with the definitions or with each other
+func checkEnumTypeNames(types, errorTypes, enumTypes []typeData) (err error) {
+	names := make(map[string]bool)
+	for _, t := range append(types, errorTypes...) {
+		names[t.Name] = true
+	}
+
+	for _, t := range enumTypes {
+		if names[t.Name] || reservedTypes[t.Name] {
+			err = errors.New("Type name of an enum property collides with another type")
+			logger.WithField("type", t.Name).Error(err)
+			return
+		}
+		names[t.Name] = true
+	}
+
+	return
+}
+
+// omitEmpty is the default for x-omitempty of the properties
+// enumTypes are the types of the enum properties, named after typeName and the property
+func createObjectProps(typeName string, definition spec.Schema, requiredProps []string, omitEmpty bool) (props []propsData, enumTypes []typeData, hasReadOnlyProps bool, err error) {
 	defer restoreLogger(logger)
 
 	requiredMap := map[string]bool{}
@@ -225,13 +476,6 @@ func createObjectProps(definition spec.Schema, requiredProps []string) (props []
 		}
 
 		isRequired := requiredMap[propName]
-		if !isRequired && val.hasValidation() {
-			// no validation can pass if the property value is not present
-			// enforce this here to make the template a bit simpler
-			err = errors.New("Properties with validation must be required")
-			logger.Error(err)
-			return
-		}
 
 		p := propsData{
 			Name:        goFormat(propName),
@@ -242,7 +486,32 @@ func createObjectProps(definition spec.Schema, requiredProps []string) (props []
 			IsRequired:  isRequired,
 		}
 
-		hasReadOnlyProps = hasReadOnlyProps || p.IsReadOnly
+		if p.IsWriteOnly, err = getBoolExtension(property.Extensions, "x-writeOnly"); err != nil {
+			return
+		}
+		if p.IsWriteOnly && p.IsReadOnly {
+			err = errors.New("A property cannot be both read-only and write-only")
+			logger.Error(err)
+			return
+		}
+
+		hasReadOnlyProps = hasReadOnlyProps || p.IsReadOnly || p.IsWriteOnly
+
+		if p.Nullable, p.NonPointer, err = getNullable(property); err != nil {
+			return
+		}
+		if p.NonPointer && (isRequired || isSlice || p.IsWriteOnly) {
+			err = errors.New("x-nullable: false is only supported for optional properties that are not arrays or write-only")
+			logger.Error(err)
+			return
+		}
+
+		p.OmitEmpty = omitEmpty
+		if _, ok := getExtension(property.Extensions, "x-omitempty"); ok {
+			if p.OmitEmpty, err = getBoolExtension(property.Extensions, "x-omitempty"); err != nil {
+				return
+			}
+		}
 
 		if goType == "struct" {
 			err = errors.New("Nested objects are not supported; use references instead")
@@ -252,8 +521,28 @@ func createObjectProps(definition spec.Schema, requiredProps []string) (props []
 			p.IsSlice = true
 			p.ItemType = goType
 			p.ItemValidation = itemVal
+
+			var enumType *typeData
+			if enumType, err = createEnumType(typeName+p.Name+"Item", goType, itemVal, *property.Items.Schema); err != nil {
+				return
+			}
+			if enumType != nil {
+				enumTypes = append(enumTypes, *enumType)
+				p.ItemType = enumType.Name
+				p.ItemValidation = validation{}
+			}
 		} else {
 			p.Type = goType
+
+			var enumType *typeData
+			if enumType, err = createEnumType(typeName+p.Name, goType, val, property); err != nil {
+				return
+			}
+			if enumType != nil {
+				enumTypes = append(enumTypes, *enumType)
+				p.Type = enumType.Name
+				p.Validation = validation{}
+			}
 		}
 
 		props = append(props, p)
@@ -262,6 +551,38 @@ func createObjectProps(definition spec.Schema, requiredProps []string) (props []
 	return
 }
 
+// x-nullable: true means the property can be null, x-nullable: false means it is never null
+func getNullable(schema spec.Schema) (nullable, nonPointer bool, err error) {
+	if _, ok := getExtension(schema.Extensions, "x-nullable"); !ok {
+		return
+	}
+
+	if nullable, err = getBoolExtension(schema.Extensions, "x-nullable"); err != nil {
+		return
+	}
+	nonPointer = !nullable
+
+	return
+}
+
+func hasNullableProps(props []propsData) bool {
+	for _, p := range props {
+		if p.Nullable || p.NonPointer {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPropValidation(props []propsData) bool {
+	for _, p := range props {
+		if p.Validation.hasValidation() || p.ItemValidation.hasValidation() {
+			return true
+		}
+	}
+	return false
+}
+
 var primitiveTypes = map[string]string{
 	"boolean": "bool",
 	"integer": "int64",
@@ -270,11 +591,66 @@ var primitiveTypes = map[string]string{
 }
 
 var goPrimitives = map[string]struct{}{
-	"bool":      struct{}{},
-	"int64":     struct{}{},
-	"float64":   struct{}{},
-	"string":    struct{}{},
-	"time.Time": struct{}{},
+	"bool":        struct{}{},
+	"int32":       struct{}{},
+	"int64":       struct{}{},
+	"uint32":      struct{}{},
+	"uint64":      struct{}{},
+	"float32":     struct{}{},
+	"float64":     struct{}{},
+	"string":      struct{}{},
+	"time.Time":   struct{}{},
+	"strfmt.Date": struct{}{},
+	"[]byte":      struct{}{},
+}
+
+// Go types of the supported integer and number formats
+var numberFormats = map[string]map[string]string{
+	"integer": {
+		"":      "int64",
+		"int32": "int32",
+		"int64": "int64",
+	},
+	"number": {
+		"":       "float64",
+		"float":  "float32",
+		"double": "float64",
+	},
+}
+
+// unsigned integer types that can be requested with x-go-type, by the signed type of the same width
+var unsignedTypes = map[string]string{
+	"int32": "uint32",
+	"int64": "uint64",
+}
+
+// Go types of the supported string formats
+// byte is base64 in json, which encoding/json does for []byte out of the box
+var stringFormats = map[string]string{
+	"":          "string",
+	"password":  "string",
+	"binary":    "string",
+	"date-time": "time.Time",
+	"date":      "strfmt.Date",
+	"byte":      "[]byte",
+	"email":     "string",
+	"uuid":      "string",
+	"uri":       "string",
+	"hostname":  "string",
+	"ipv4":      "string",
+	"ipv6":      "string",
+	"mac":       "string",
+}
+
+// string formats that are checked with the strfmt registry in the generated code
+var validatedStringFormats = map[string]bool{
+	"email":    true,
+	"uuid":     true,
+	"uri":      true,
+	"hostname": true,
+	"ipv4":     true,
+	"ipv6":     true,
+	"mac":      true,
 }
 
 func getType(schema spec.Schema) (t string, val, itemVal validation, isSlice bool, err error) {
@@ -299,15 +675,27 @@ func getType(schema spec.Schema) (t string, val, itemVal validation, isSlice boo
 
 	var ok bool
 	if t, ok = primitiveTypes[schemaType]; ok {
-		if t == "string" && schema.Format != "" {
-			if schema.Format != "date-time" && schema.Format != "password" {
-				err = errors.New("Unsupported string format")
+		if formats, isNumber := numberFormats[schemaType]; isNumber {
+			if t, ok = formats[schema.Format]; !ok {
+				err = errors.New("Unsupported number format")
 				logger.WithField("format", schema.Format).Error(err)
 				return
 			}
 
-			if schema.Format == "date-time" {
-				t = "time.Time"
+			if t, err = getGoTypeExtension(schemaType, t, schema); err != nil {
+				return
+			}
+		}
+
+		if t == "string" {
+			if t, ok = stringFormats[schema.Format]; !ok {
+				format, isCustom := customFormats[schema.Format]
+				if !isCustom {
+					err = errors.New("Unsupported string format")
+					logger.WithField("format", schema.Format).Error(err)
+					return
+				}
+				t = format.Type
 			}
 		}
 	} else if schemaType == "object" {
@@ -343,7 +731,7 @@ func getType(schema spec.Schema) (t string, val, itemVal validation, isSlice boo
 	val, err = getValidationForType(t, isSlice, schema)
 
 	// this shouldn't be here but in the validation part we don't have enough context
-	if _, ok := goPrimitives[t]; val.Array != nil && val.Array.UniqueItems && !ok {
+	if _, ok := goPrimitives[t]; val.Array != nil && val.Array.UniqueItems && (!ok || t == "[]byte") {
 		err = errors.New("Only primitive arrays can be enforced to be unique")
 		logger.Error(err)
 	}
@@ -351,63 +739,74 @@ func getType(schema spec.Schema) (t string, val, itemVal validation, isSlice boo
 	return
 }
 
+// x-go-type requests a specific Go type for an integer, typically an unsigned one.
+// When the format sets the width, the Go type must have the same width.
+func getGoTypeExtension(schemaType, formatType string, schema spec.Schema) (t string, err error) {
+	t = formatType
+
+	extension, ok := getExtension(schema.Extensions, "x-go-type")
+	if !ok {
+		return
+	}
+
+	goType, _ := extension.(string)
+	_, isInteger := intRanges[goType]
+	if schemaType != "integer" || !isInteger || (schema.Format != "" && goType != formatType && goType != unsignedTypes[formatType]) {
+		err = errors.New("Unsupported x-go-type")
+		logger.WithFields(log.Fields{
+			"goType": extension,
+			"format": schema.Format,
+		}).Error(err)
+		return
+	}
+
+	t = goType
+	return
+}
+
 // Swagger objects with read-only properties lead to two Go structs, one with the read-only
-// properties and one with the rest. If we reference such an object from another object, we need
-// to also create two Go structs for that one. Not impossible, but it leads to annoying bookkeeping.
+// properties and one with the rest. The read-only variant is used in responses, the other one in
+// request bodies.
 //
-// For now we just forbid that case: an object with read-only properties cannot be referenced by
-// another object. There is one exception: top-level arrays may reference objects with read-only
-// properties. This is the typical response for the GET /<resources> endpoint, so forbidding that
-// makes no sense.
-func checkReadOnlyTypes(model *modelData) (readOnlyTypes map[string]bool, err error) {
-	defer restoreLogger(logger)
-
-	// names of types that have read-only properties
+// Every type that references such an object, directly or through other types, gets a read-only
+// variant as well, in which the references point to the read-only variants. This computes the
+// transitive closure of the referencing types and marks the properties that need the read-only
+// variant of their type.
+func getReadOnlyTypes(types []typeData) (readOnlyTypes map[string]bool) {
+	// names of types that have a read-only variant
 	readOnlyTypes = make(map[string]bool)
-	for _, t := range model.Types {
+	for _, t := range types {
 		if t.HasReadOnlyProps {
 			readOnlyTypes[t.Name] = true
 		}
 	}
 
-	readOnlyArrays := make(map[string]bool)
+	// mark referencing types until nothing changes
+	for changed := true; changed; {
+		changed = false
 
-	// check that read-only objects are only referenced by top-level arrays
-	for i := range model.Types {
-		t := &model.Types[i]
+		for i := range types {
+			t := &types[i]
 
-		logger = logger.WithFields(log.Fields{
-			"type": t.Name,
-		})
-
-		for _, dep := range getDependencies(t) {
-			logger = logger.WithFields(log.Fields{
-				"reference": dep,
-			})
+			if readOnlyTypes[t.Name] {
+				continue
+			}
 
-			if readOnlyTypes[dep] {
-				if t.IsSlice {
-					// the only allowed case: a top level array referencing a type with read-only properties
+			for _, dep := range getDependencies(t) {
+				if readOnlyTypes[dep] {
 					t.HasReadOnlyProps = true
-					readOnlyArrays[t.Name] = true
 					readOnlyTypes[t.Name] = true
-				} else {
-					err = errors.New("Cannot $ref a type with read-only properties")
-					logger.Error(err)
-					return
+					changed = true
+					break
 				}
 			}
 		}
 	}
 
-	// verify that the top-level arrays are not referenced again
-	for _, t := range model.Types {
-		for _, dep := range getDependencies(&t) {
-			if readOnlyArrays[dep] {
-				err = errors.New("Cannot $ref a read-only array")
-				logger.Error(err)
-				return
-			}
+	for i := range types {
+		for j := range types[i].Props {
+			p := &types[i].Props[j]
+			p.HasReadOnlyType = readOnlyTypes[p.Type] || readOnlyTypes[p.ItemType]
 		}
 	}
 
@@ -435,6 +834,68 @@ func getDependencies(t *typeData) (dependencies []string) {
 	return
 }
 
+// A type allows additional properties if it, or any type it references, explicitly allows them.
+// A type forbids them if it, or any type it references, explicitly forbids them. The JSON decoder can only reject
+// unknown properties for a body as a whole, so a type cannot do both.
+func getAdditionalPropsTypes(types []typeData) (additionalPropsTypes map[string]bool, err error) {
+	defer restoreLogger(logger)
+
+	allowing := propagateAdditionalProps(types, true)
+	forbidding := propagateAdditionalProps(types, false)
+
+	additionalPropsTypes = make(map[string]bool)
+
+	for _, t := range types {
+		switch {
+		case allowing[t.Name] && forbidding[t.Name]:
+			err = errors.New("Types that forbid additional properties cannot be combined with types that allow them")
+			logger.WithField("type", t.Name).Error(err)
+			return
+		case allowing[t.Name]:
+			additionalPropsTypes[t.Name] = true
+		case forbidding[t.Name]:
+			additionalPropsTypes[t.Name] = false
+		}
+	}
+
+	return
+}
+
+// propagateAdditionalProps returns the types that explicitly allow or forbid additional properties, and the types
+// that reference them
+func propagateAdditionalProps(types []typeData, allowed bool) (found map[string]bool) {
+	found = make(map[string]bool)
+
+	for _, t := range types {
+		if t.AdditionalProps != nil && *t.AdditionalProps == allowed {
+			found[t.Name] = true
+		}
+	}
+
+	// propagate to referencing types, until nothing changes
+	for changed := true; changed; {
+		changed = false
+
+		for i := range types {
+			t := &types[i]
+
+			if found[t.Name] {
+				continue
+			}
+
+			for _, dep := range getDependencies(t) {
+				if found[dep] {
+					found[t.Name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	return
+}
+
 func linkReferences(types ...[]typeData) {
 	allTypes := make(map[string]typeData)
 
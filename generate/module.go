@@ -0,0 +1,89 @@
+package generate
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/mod/modfile"
+)
+
+// import path of the folder of the swagger file, for projects without a go.mod
+var explicitModule string
+
+// SetModule sets the import path of the folder that contains the swagger file
+// It is only used when there is no go.mod in that folder or one of its parents
+func SetModule(module string) {
+	explicitModule = strings.TrimSuffix(module, "/")
+}
+
+// getImportPath returns the import path of the Go package in folder
+// It uses the go.mod that encloses the swagger file, then the module set with SetModule, and finally GOPATH
+func getImportPath(swaggerDir, folder string) (importPath string, err error) {
+	defer restoreLogger(logger)
+	logger = logger.WithField("folder", folder)
+
+	var root, module string
+	if root, module, err = findModule(swaggerDir); err != nil {
+		return
+	}
+
+	if module == "" && explicitModule != "" {
+		root, module = swaggerDir, explicitModule
+	}
+
+	if module == "" && os.Getenv("GOPATH") != "" {
+		// legacy GOPATH project: the import path is the path below $GOPATH/src
+		root = filepath.Join(os.Getenv("GOPATH"), "src")
+	}
+
+	var rel string
+	if rel, err = filepath.Rel(root, folder); err != nil || root == "" || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		err = errors.New("Cannot determine the import path of the generated code; add a go.mod or set the module")
+		logger.WithFields(log.Fields{
+			"root":   root,
+			"module": module,
+		}).Error(err)
+		return
+	}
+
+	importPath = filepath.ToSlash(rel)
+	if module != "" {
+		importPath = module
+		if rel != "." {
+			importPath += "/" + filepath.ToSlash(rel)
+		}
+	}
+
+	return
+}
+
+// findModule looks for a go.mod in dir and its parents
+// root and module are empty if there is none
+func findModule(dir string) (root, module string, err error) {
+	for current := dir; ; current = filepath.Dir(current) {
+		path := filepath.Join(current, "go.mod")
+
+		var data []byte
+		if data, err = ioutil.ReadFile(path); err == nil {
+			if module = modfile.ModulePath(data); module == "" {
+				err = errors.New("go.mod does not have a module path")
+				logger.WithField("goMod", path).Error(err)
+				return
+			}
+			root = current
+			return
+		} else if !os.IsNotExist(err) {
+			logger.WithField("goMod", path).Error(err)
+			return
+		}
+		err = nil
+
+		if filepath.Dir(current) == current {
+			return
+		}
+	}
+}
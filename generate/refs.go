@@ -0,0 +1,287 @@
+package generate
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	log "github.com/sirupsen/logrus"
+)
+
+// definitionImporter copies definitions from other files into the definitions of the main spec
+type definitionImporter struct {
+	swagger  *spec.Swagger
+	rootPath string
+	// file that each definition comes from, by name
+	files map[string]string
+}
+
+// importExternalDefinitions adds the definitions that are referenced in other files to the definitions of the
+// spec, and turns all references into references to the spec itself
+// rootPath is the path of the swagger file, which relative references are resolved against
+// externalDefinitions contains the file of every imported definition, by name
+func importExternalDefinitions(swagger *spec.Swagger, rootPath string) (externalDefinitions map[string]string, err error) {
+	importer := definitionImporter{
+		swagger:  swagger,
+		rootPath: rootPath,
+		files:    map[string]string{},
+	}
+
+	if swagger.Definitions == nil {
+		swagger.Definitions = spec.Definitions{}
+	}
+
+	// the definitions map grows while importing, so only walk over the definitions of the spec itself
+	names := make([]string, 0, len(swagger.Definitions))
+	for name := range swagger.Definitions {
+		names = append(names, name)
+		importer.files[name] = rootPath
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		definition := swagger.Definitions[name]
+		if err = importer.importRefs(&definition, rootPath); err != nil {
+			return
+		}
+		swagger.Definitions[name] = definition
+	}
+
+	for _, schema := range importer.routeSchemas() {
+		if err = importer.importRefs(schema, rootPath); err != nil {
+			return
+		}
+	}
+
+	externalDefinitions = map[string]string{}
+	for name, file := range importer.files {
+		if file != rootPath {
+			externalDefinitions[name] = file
+		}
+	}
+
+	return
+}
+
+// routeSchemas returns the schemas of the parameters and responses in the spec
+func (i *definitionImporter) routeSchemas() (schemas []*spec.Schema) {
+	addParams := func(params []spec.Parameter) {
+		for _, param := range params {
+			if param.Schema != nil {
+				schemas = append(schemas, param.Schema)
+			}
+		}
+	}
+	addResponse := func(response *spec.Response) {
+		if response != nil && response.Schema != nil {
+			schemas = append(schemas, response.Schema)
+		}
+	}
+
+	for _, param := range i.swagger.Parameters {
+		addParams([]spec.Parameter{param})
+	}
+	for _, response := range i.swagger.Responses {
+		addResponse(&response)
+	}
+
+	if i.swagger.Paths == nil {
+		return
+	}
+
+	for _, pathItem := range i.swagger.Paths.Paths {
+		addParams(pathItem.Parameters)
+
+		for _, operation := range []*spec.Operation{pathItem.Get, pathItem.Put, pathItem.Post, pathItem.Delete, pathItem.Options, pathItem.Head, pathItem.Patch} {
+			if operation == nil {
+				continue
+			}
+
+			addParams(operation.Parameters)
+
+			if operation.Responses != nil {
+				addResponse(operation.Responses.Default)
+				for _, response := range operation.Responses.StatusCodeResponses {
+					addResponse(&response)
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// importRefs imports the definitions that schema, which is located in file, references
+func (i *definitionImporter) importRefs(schema *spec.Schema, file string) error {
+	return walkSchema(schema, func(s *spec.Schema) error {
+		if s.Ref.String() == "" {
+			return nil
+		}
+		return i.importRef(&s.Ref, file)
+	})
+}
+
+func (i *definitionImporter) importRef(ref *spec.Ref, file string) (err error) {
+	defer restoreLogger(logger)
+	logger = logger.WithFields(log.Fields{
+		"ref":  ref.String(),
+		"file": file,
+	})
+
+	url := ref.GetURL()
+	if url == nil {
+		err = errors.New("Ref doesn't have a url")
+		logger.Error(err)
+		return
+	}
+
+	if url.Host != "" || (url.Scheme != "" && url.Scheme != "file") {
+		err = errors.New("Only references to local files are supported")
+		logger.Error(err)
+		return
+	}
+
+	// a reference without a path is relative to the file that contains it
+	target := file
+	if url.Path != "" {
+		target = resolvePath(filepath.Dir(file), filepath.FromSlash(url.Path))
+	}
+
+	localRef := spec.MustCreateRef("#" + url.Fragment)
+
+	var name string
+	if name, err = getDefinitionName(localRef); err != nil {
+		return
+	}
+
+	if target == i.rootPath {
+		*ref = localRef
+		return
+	}
+
+	if existingFile, ok := i.files[name]; ok {
+		if existingFile != target {
+			err = errors.New("Definitions in different files have the same name")
+			logger.WithFields(log.Fields{
+				"definition": name,
+				"files":      []string{existingFile, target},
+			}).Error(err)
+			return
+		}
+
+		*ref = localRef
+		return
+	}
+
+	// register the definition before importing its references, for definitions that reference themselves
+	i.files[name] = target
+
+	absoluteRef := spec.MustCreateRef(filepath.ToSlash(target) + "#" + url.Fragment)
+
+	var definition *spec.Schema
+	if definition, err = spec.ResolveRefWithBase(i.swagger, &absoluteRef, &spec.ExpandOptions{RelativeBase: i.rootPath}); err != nil {
+		logger.Error(err)
+		return
+	}
+
+	if err = i.importRefs(definition, target); err != nil {
+		return
+	}
+
+	i.swagger.Definitions[name] = *definition
+	*ref = localRef
+
+	return
+}
+
+// getDefinitionName returns the name of the definition that a local ref points to, as written in the spec
+func getDefinitionName(ref spec.Ref) (name string, err error) {
+	return getLocalRefName(ref, "definitions")
+}
+
+// getLocalRefName returns the name of the definition, parameter or response that a local ref points to
+// section is definitions, parameters or responses
+func getLocalRefName(ref spec.Ref, section string) (name string, err error) {
+	url := ref.GetURL()
+	if url == nil {
+		err = errors.New("Ref doesn't have a url")
+		logger.Error(err)
+		return
+	}
+
+	parts := strings.Split(url.Fragment, "/")
+	if len(parts) != 3 || parts[0] != "" || parts[1] != section || url.Path != "" {
+		err = errors.New("Only references to " + section + " of the spec are supported")
+		logger.WithField("ref", ref.String()).Error(err)
+		return
+	}
+
+	name = parts[2]
+	return
+}
+
+// walkSchema calls visit for schema and every schema inside it
+func walkSchema(schema *spec.Schema, visit func(*spec.Schema) error) (err error) {
+	if err = visit(schema); err != nil {
+		return
+	}
+
+	walkMap := func(schemas map[string]spec.Schema) (err error) {
+		for name, s := range schemas {
+			if err = walkSchema(&s, visit); err != nil {
+				return
+			}
+			schemas[name] = s
+		}
+		return
+	}
+	walkSlice := func(schemas []spec.Schema) (err error) {
+		for i := range schemas {
+			if err = walkSchema(&schemas[i], visit); err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	if err = walkMap(schema.Properties); err != nil {
+		return
+	}
+	if err = walkMap(schema.PatternProperties); err != nil {
+		return
+	}
+	if schema.Items != nil {
+		if schema.Items.Schema != nil {
+			if err = walkSchema(schema.Items.Schema, visit); err != nil {
+				return
+			}
+		}
+		if err = walkSlice(schema.Items.Schemas); err != nil {
+			return
+		}
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		if err = walkSchema(schema.AdditionalProperties.Schema, visit); err != nil {
+			return
+		}
+	}
+	if schema.AdditionalItems != nil && schema.AdditionalItems.Schema != nil {
+		if err = walkSchema(schema.AdditionalItems.Schema, visit); err != nil {
+			return
+		}
+	}
+	if schema.Not != nil {
+		if err = walkSchema(schema.Not, visit); err != nil {
+			return
+		}
+	}
+	for _, schemas := range [][]spec.Schema{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		if err = walkSlice(schemas); err != nil {
+			return
+		}
+	}
+
+	return
+}
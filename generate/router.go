@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/fujitsueos/go-server-generator/templates"
@@ -14,14 +15,27 @@ import (
 )
 
 type routerData struct {
+	Package                      string
 	Routes                       []routeData
 	ModelPackage                 string
+	ModelPackageName             string
 	BadRequestErrors             []string
 	InternalServerErrors         []string
 	AllErrors                    []errorTypeData
 	HasParameterArray            bool
 	HasParameterArrayValidation  bool
 	HasParameterStringValidation bool
+	HasParameterNumber           bool
+	HasParameterTime             bool
+	HasParameterValidation       bool
+	Patterns                     []patternData
+	Enums                        []typeData
+	SharedParams                 []paramData
+	TagHandlers                  []tagHandlerData
+	HasEventStream               bool
+	HasBody                      bool
+	HasBodySizeLimit             bool
+	Imports                      []string
 }
 
 type routeData struct {
@@ -42,15 +56,34 @@ type routeData struct {
 	ValidationError *string
 	CatchAllError   *string
 
+	// event stream fields; EventType is set instead of ResultType
+	EventType     string
+	ReadOnlyEvent bool
+
 	Tag string
 
 	// meta-properties for the template
 	NewBlock bool
 }
 
-type bodyData struct {
+// with x-tag-handlers, Handler is composed of an interface per tag
+type tagHandlerData struct {
+	Tag  string
 	Name string
-	Type string
+}
+
+type bodyData struct {
+	Name                  string
+	Type                  string
+	MaxSize               int64
+	DisallowUnknownFields bool
+	Strict                bool
+}
+
+// rules for decoding request bodies, set on the swagger root and overridable per operation
+type bodyRules struct {
+	MaxSize int64
+	Strict  bool
 }
 
 type paramData struct {
@@ -62,6 +95,27 @@ type paramData struct {
 	ItemValidation *stringValidation
 	Required       bool
 	IsArray        bool
+
+	// integer and number fields
+	IsNumber bool
+	BitSize  int
+
+	// name of the regexp variables for the pattern of the parameter and of its items
+	RegexpName string
+
+	// date and date-time fields; the layout is a Go expression
+	IsTime     bool
+	TimeLayout string
+	TimeFormat string
+
+	// string and integer enums get a type of their own, named after the handler and the parameter
+	// for arrays, this is the type of the items, and Type is the name of that type
+	Enum *enumData
+
+	// parameters that are referenced from the parameters of the spec are parsed by a helper, named after the
+	// parameter in the spec; empty for the parameters of an operation
+	Shared        string
+	HasValidation bool
 }
 
 type errorData struct {
@@ -82,13 +136,18 @@ type errorRouteData struct {
 }
 
 // Router generates the model based on a definitions spec
-func Router(routerWriter, routeErrorsWriter io.Writer, paths *spec.Paths, readOnlyTypes map[string]bool, modelPackage string) (err error) {
+// packageName is the name of the Go package of the router; modelPackage is the import path of the model and
+// modelPackageName its name, where the route errors are generated
+func Router(routerWriter, routeErrorsWriter io.Writer, swagger *spec.Swagger, readOnlyTypes, additionalPropsTypes map[string]bool, packageName, modelPackage, modelPackageName string) (err error) {
 	var router routerData
-	if router, err = createRouter(paths, readOnlyTypes); err != nil {
+	if router, err = createRouter(swagger, readOnlyTypes, additionalPropsTypes); err != nil {
 		return
 	}
 
+	router.Package = packageName
 	router.ModelPackage = modelPackage
+	router.ModelPackageName = modelPackageName
+	router.Imports = customFormatImports()
 
 	if err = templates.Router.Execute(routerWriter, router); err != nil {
 		return
@@ -97,8 +156,17 @@ func Router(routerWriter, routeErrorsWriter io.Writer, paths *spec.Paths, readOn
 	return
 }
 
-func createRouter(paths *spec.Paths, readOnlyTypes map[string]bool) (router routerData, err error) {
-	if router, err = createRouterFromPaths(paths, readOnlyTypes); err != nil {
+func createRouter(swagger *spec.Swagger, readOnlyTypes, additionalPropsTypes map[string]bool) (router routerData, err error) {
+	var defaultBodyRules bodyRules
+	if defaultBodyRules, err = getBodyRules(swagger.Extensions, bodyRules{}); err != nil {
+		return
+	}
+
+	if router, err = createRouterFromPaths(swagger, defaultBodyRules, readOnlyTypes, additionalPropsTypes); err != nil {
+		return
+	}
+
+	if router.SharedParams, err = listSharedParams(router.Routes); err != nil {
 		return
 	}
 
@@ -108,10 +176,31 @@ func createRouter(paths *spec.Paths, readOnlyTypes map[string]bool) (router rout
 
 	router.HasParameterArray, router.HasParameterArrayValidation, router.HasParameterStringValidation = getParametersChecks(router.Routes)
 
+	for _, route := range router.Routes {
+		for _, param := range route.Params {
+			router.HasParameterNumber = router.HasParameterNumber || param.IsNumber
+			router.HasParameterTime = router.HasParameterTime || param.IsTime
+			router.HasParameterValidation = router.HasParameterValidation || param.HasValidation
+		}
+		if route.EventType != "" {
+			router.HasEventStream = true
+		}
+		if route.Body != nil {
+			router.HasBody = true
+			router.HasBodySizeLimit = router.HasBodySizeLimit || route.Body.MaxSize > 0
+		}
+	}
+
 	groupErrors(&router)
 
 	sortRouter(&router)
 
+	listParamPatterns(&router)
+
+	if err = listParamEnums(&router); err != nil {
+		return
+	}
+
 	prevTag := ""
 	for i := range router.Routes {
 		route := &router.Routes[i]
@@ -123,15 +212,49 @@ func createRouter(paths *spec.Paths, readOnlyTypes map[string]bool) (router rout
 		prevTag = route.Tag
 	}
 
+	if tagHandlers, _ := swagger.Extensions.GetBool("x-tag-handlers"); tagHandlers {
+		if router.TagHandlers, err = listTagHandlers(router.Routes); err != nil {
+			return
+		}
+	}
+
 	return
 }
 
-func createRouterFromPaths(paths *spec.Paths, readOnlyTypes map[string]bool) (router routerData, err error) {
+// listTagHandlers returns an interface for each tag of the routes, which must be sorted by tag
+func listTagHandlers(routes []routeData) (tagHandlers []tagHandlerData, err error) {
+	names := map[string]string{}
+	for _, route := range routes {
+		if !route.NewBlock {
+			continue
+		}
+
+		name := goFormat(route.Tag) + "Handler"
+		if tag, exists := names[name]; exists {
+			err = errors.New("Tags lead to the same handler name")
+			logger.WithFields(log.Fields{
+				"handler": name,
+				"tags":    []string{tag, route.Tag},
+			}).Error(err)
+			return
+		}
+		names[name] = route.Tag
+
+		tagHandlers = append(tagHandlers, tagHandlerData{
+			Tag:  route.Tag,
+			Name: name,
+		})
+	}
+
+	return
+}
+
+func createRouterFromPaths(swagger *spec.Swagger, defaultBodyRules bodyRules, readOnlyTypes, additionalPropsTypes map[string]bool) (router routerData, err error) {
 	defer restoreLogger(logger)
 
 	var r routeData
 
-	for path, pathItem := range paths.Paths {
+	for path, pathItem := range swagger.Paths.Paths {
 		logger = logger.WithField("path", path)
 
 		operations := map[string]*spec.Operation{
@@ -143,7 +266,7 @@ func createRouterFromPaths(paths *spec.Paths, readOnlyTypes map[string]bool) (ro
 
 		for method, operation := range operations {
 			if operation != nil {
-				if r, err = createRouteData(method, path, operation, pathItem.Parameters, readOnlyTypes); err != nil {
+				if r, err = createRouteData(method, path, operation, pathItem.Parameters, swagger, defaultBodyRules, readOnlyTypes, additionalPropsTypes); err != nil {
 					return
 				}
 
@@ -161,7 +284,8 @@ func createRouterFromPaths(paths *spec.Paths, readOnlyTypes map[string]bool) (ro
 	return
 }
 
-func createRouteData(method, path string, operation *spec.Operation, routeParameters []spec.Parameter, readOnlyTypes map[string]bool) (r routeData, err error) {
+// swagger is used to resolve references to the parameters and responses of the spec
+func createRouteData(method, path string, operation *spec.Operation, routeParameters []spec.Parameter, swagger *spec.Swagger, defaultBodyRules bodyRules, readOnlyTypes, additionalPropsTypes map[string]bool) (r routeData, err error) {
 	defer restoreLogger(logger)
 	logger = logger.WithField("method", method)
 
@@ -171,13 +295,22 @@ func createRouteData(method, path string, operation *spec.Operation, routeParame
 		return
 	}
 
-	if len(operation.Tags) > 1 {
-		err = errors.New("Multiple tags are not supported")
-		logger.Error(err)
+	var tag string
+	if tag, err = getPrimaryTag(operation); err != nil {
+		return
+	}
+
+	// names of the referenced parameters of the spec, by location and name
+	sharedParams := map[string]string{}
+	var resolvedRouteParams, resolvedOperationParams []spec.Parameter
+	if resolvedRouteParams, err = resolveParams(routeParameters, swagger.Parameters, sharedParams); err != nil {
+		return
+	}
+	if resolvedOperationParams, err = resolveParams(operation.Parameters, swagger.Parameters, sharedParams); err != nil {
 		return
 	}
 
-	paramMap := mergeParams(routeParameters, operation.Parameters)
+	paramMap := mergeParams(resolvedRouteParams, resolvedOperationParams)
 	if len(paramMap["formData"]) > 0 {
 		err = errors.New("formData parameters are not supported")
 		logger.Error(err)
@@ -191,14 +324,15 @@ func createRouteData(method, path string, operation *spec.Operation, routeParame
 		Route:       formatParams(path),
 		Name:        lowerStart(handlerName),
 		HandlerName: handlerName,
-		Tag:         "Other",
+		Tag:         tag,
 	}
 
-	if len(operation.Tags) == 1 {
-		r.Tag = operation.Tags[0]
+	var rules bodyRules
+	if rules, err = getBodyRules(operation.Extensions, defaultBodyRules); err != nil {
+		return
 	}
 
-	if r.Body, err = createBodyData(paramMap["body"]["body"]); err != nil {
+	if r.Body, err = createBodyData(paramMap["body"]["body"], rules, additionalPropsTypes); err != nil {
 		return
 	}
 	r.HasValidation = r.Body != nil
@@ -208,7 +342,7 @@ func createRouteData(method, path string, operation *spec.Operation, routeParame
 			params        []paramData
 			hasValidation bool
 		)
-		if params, hasValidation, err = createParamData(p, paramMap[p]); err != nil {
+		if params, hasValidation, err = createParamData(p, handlerName, paramMap[p], sharedParams); err != nil {
 			return
 		}
 
@@ -223,14 +357,81 @@ func createRouteData(method, path string, operation *spec.Operation, routeParame
 		r.HasValidation = (r.HasValidation || hasValidation)
 	}
 
-	r.ResultType, r.IsResultSlice, r.ReadOnlyResult, r.ResultErrors, err = createResultType(operation.Responses, readOnlyTypes)
+	if r.ResultType, r.IsResultSlice, r.ReadOnlyResult, r.ResultErrors, err = createResultType(operation.Responses, swagger.Responses, readOnlyTypes); err != nil {
+		return
+	}
 	r.ValidationError = getError(r.ResultErrors, http.StatusBadRequest)
 	r.CatchAllError = getError(r.ResultErrors, http.StatusInternalServerError)
 
+	var eventStream bool
+	if eventStream, err = isEventStream(operation.Produces); err != nil || !eventStream {
+		return
+	}
+
+	if r.ResultType == "" || r.IsResultSlice {
+		err = errors.New("Event streams must reference a single event type in the success response")
+		logger.Error(err)
+		return
+	}
+
+	// the success response describes a single event instead of the response body
+	r.EventType, r.ReadOnlyEvent = r.ResultType, r.ReadOnlyResult
+	r.ResultType, r.ReadOnlyResult = "", false
+
 	return
 }
 
-func createBodyData(bodyParam *spec.Parameter) (body *bodyData, err error) {
+// getPrimaryTag returns the tag that the operation is grouped by in the Handler
+// Operations with multiple tags choose one of them with x-primary-tag
+func getPrimaryTag(operation *spec.Operation) (tag string, err error) {
+	primaryTag, hasPrimaryTag := operation.Extensions.GetString("x-primary-tag")
+	if !hasPrimaryTag {
+		switch len(operation.Tags) {
+		case 0:
+			tag = "Other"
+		case 1:
+			tag = operation.Tags[0]
+		default:
+			err = errors.New("Operations with multiple tags need x-primary-tag")
+			logger.WithField("tags", operation.Tags).Error(err)
+		}
+		return
+	}
+
+	for _, t := range operation.Tags {
+		if t == primaryTag {
+			tag = primaryTag
+			return
+		}
+	}
+
+	err = errors.New("x-primary-tag must be one of the tags of the operation")
+	logger.WithFields(log.Fields{
+		"primaryTag": primaryTag,
+		"tags":       operation.Tags,
+	}).Error(err)
+	return
+}
+
+const eventStreamMimeType = "text/event-stream"
+
+// an operation is an event stream if it produces text/event-stream, and nothing else
+func isEventStream(produces []string) (eventStream bool, err error) {
+	for _, mimeType := range produces {
+		if mimeType == eventStreamMimeType {
+			eventStream = true
+		}
+	}
+
+	if eventStream && len(produces) > 1 {
+		err = errors.New("Event streams cannot produce other content types")
+		logger.WithField("produces", produces).Error(err)
+	}
+
+	return
+}
+
+func createBodyData(bodyParam *spec.Parameter, rules bodyRules, additionalPropsTypes map[string]bool) (body *bodyData, err error) {
 	// no body
 	if bodyParam == nil {
 		return
@@ -247,18 +448,62 @@ func createBodyData(bodyParam *spec.Parameter) (body *bodyData, err error) {
 		return
 	}
 
+	allowsAdditionalProps, explicit := additionalPropsTypes[bodyType]
+
 	body = &bodyData{
-		Name: "body" + goFormat(bodyParam.Name),
-		Type: bodyType,
+		Name:    "body" + goFormat(bodyParam.Name),
+		Type:    bodyType,
+		MaxSize: rules.MaxSize,
+		Strict:  rules.Strict,
+		// additionalProperties: false is respected even when not in strict mode, also in nested types
+		DisallowUnknownFields: rules.Strict || (explicit && !allowsAdditionalProps),
+	}
+
+	// the decoder can only reject unknown fields for the body as a whole
+	if body.DisallowUnknownFields && allowsAdditionalProps {
+		err = errors.New("Strict JSON decoding is not supported for types that allow additional properties")
+		logger.WithField("bodyType", bodyType).Error(err)
+		return
+	}
+
+	return
+}
+
+// x-max-body-size is the maximum body size in bytes, x-strict-json rejects unknown properties and trailing data
+func getBodyRules(extensions spec.Extensions, defaults bodyRules) (rules bodyRules, err error) {
+	rules = defaults
+
+	var (
+		maxSize int64
+		ok      bool
+	)
+	if maxSize, ok, err = getIntExtension(extensions, "x-max-body-size"); err != nil {
+		return
+	}
+	if ok {
+		if maxSize <= 0 {
+			err = errors.New("x-max-body-size must be positive")
+			logger.WithField("maxBodySize", maxSize).Error(err)
+			return
+		}
+		rules.MaxSize = maxSize
+	}
+
+	if strict, ok := extensions.GetBool("x-strict-json"); ok {
+		rules.Strict = strict
 	}
 
 	return
 }
 
-func createParamData(location string, params map[string]*spec.Parameter) (data []paramData, hasValidation bool, err error) {
+// sharedParams contains the names of the referenced parameters of the spec, by location and name
+func createParamData(location, handlerName string, params map[string]*spec.Parameter, sharedParams map[string]string) (data []paramData, hasValidation bool, err error) {
 	defer restoreLogger(logger)
 
 	for _, param := range params {
+		routeHasValidation := hasValidation
+		hasValidation = false
+
 		logger = logger.WithFields(log.Fields{
 			"parameter":         param.Name,
 			"parameterType":     param.Type,
@@ -266,8 +511,9 @@ func createParamData(location string, params map[string]*spec.Parameter) (data [
 			"parameterFormat":   param.Format,
 		})
 
-		if !(param.Type == "string" || param.Type == "array") {
-			err = errors.New("Only strings, dates and arrays are supported in path and query")
+		_, isNumber := numberFormats[param.Type]
+		if !(param.Type == "string" || param.Type == "array" || isNumber) {
+			err = errors.New("Only strings, numbers, dates and arrays are supported in path, query and header")
 			logger.Error(err)
 			return
 		}
@@ -278,37 +524,95 @@ func createParamData(location string, params map[string]*spec.Parameter) (data [
 			RawName:  param.Name,
 			Required: param.Required,
 			Type:     "string",
+			Shared:   sharedParams[location+"/"+param.Name],
+		}
+
+		// enums of shared parameters are named after the parameter in the spec, as they don't belong to one handler
+		enumName := handlerName + goFormat(param.Name)
+		if pData.Shared != "" {
+			enumName = pData.Shared
 		}
 
 		if param.Type == "string" {
-			if !(param.Format == "" || param.Format == "date-time" || param.Format == "password") {
+			format, isCustom := customFormats[param.Format]
+			_, isTime := timeLayouts[param.Format]
+			if !(stringFormats[param.Format] == "string" || isTime || isCustom) {
 				err = errors.New("Unsupported string format")
 				logger.Error(err)
 				return
 			}
 
-			if param.Format == "date-time" {
+			if isCustom {
+				pData.Type = format.Type
+				pData.Validation.Custom = &customValidation{
+					Format:   param.Format,
+					Validate: format.Validate,
+					Parse:    format.Parse,
+				}
+				hasValidation = true
+
+				if err = checkUnsupportedParamValidation(param.CommonValidations, []string{}); err != nil {
+					return
+				}
+			} else if isTime {
+				pData.IsTime = true
 				pData.Type = "time.Time"
+				if !pData.Required {
+					// nil when the parameter is missing
+					pData.Type = "*time.Time"
+				}
+				if pData.TimeLayout, pData.TimeFormat, err = getTimeLayout(param); err != nil {
+					return
+				}
 				hasValidation = true
 
 				if err = checkUnsupportedParamValidation(param.CommonValidations, []string{}); err != nil {
 					return
 				}
 			} else {
-				if pData.Validation, err = getParamValidation("string", param.CommonValidations); err != nil {
+				if pData.Validation, err = getParamValidation("string", param.Format, param.CommonValidations); err != nil {
 					return
 				}
 
-				if err = checkUnsupportedParamValidation(param.CommonValidations, []string{"minLength", "maxLength", "enum"}); err != nil {
+				if err = checkUnsupportedParamValidation(param.CommonValidations, []string{"minLength", "maxLength", "pattern", "enum"}); err != nil {
 					return
 				}
 
 				hasValidation = hasValidation || pData.Validation.String != nil
+
+				if err = setParamEnum(&pData, enumName, pData.Validation, param.Extensions); err != nil {
+					return
+				}
 			}
+		} else if isNumber {
+			pData.IsNumber = true
+
+			var ok bool
+			if pData.Type, ok = numberFormats[param.Type][param.Format]; !ok {
+				err = errors.New("Unsupported number format")
+				logger.Error(err)
+				return
+			}
+			pData.BitSize = bitSizes[pData.Type]
+
+			if pData.Validation, err = getParamValidation(pData.Type, "", param.CommonValidations); err != nil {
+				return
+			}
+
+			if err = checkUnsupportedParamValidation(param.CommonValidations, []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf", "enum"}); err != nil {
+				return
+			}
+
+			if err = setParamEnum(&pData, enumName, pData.Validation, param.Extensions); err != nil {
+				return
+			}
+
+			// parsing the number can fail
+			hasValidation = true
 		} else { // "array"
 			pData.IsArray = true
 
-			if !(param.Items.Type == "string" && param.Items.Format == "") {
+			if !(param.Items.Type == "string" && stringFormats[param.Items.Format] == "string") {
 				err = errors.New("Only arrays of strings are supported")
 				logger.Error(err)
 				return
@@ -320,11 +624,11 @@ func createParamData(location string, params map[string]*spec.Parameter) (data [
 				return
 			}
 
-			if pData.Validation, err = getParamValidation("array", param.CommonValidations); err != nil {
+			if pData.Validation, err = getParamValidation("array", "", param.CommonValidations); err != nil {
 				return
 			}
 			var itemValidation validation
-			if itemValidation, err = getParamValidation("string", param.Items.CommonValidations); err != nil {
+			if itemValidation, err = getParamValidation("string", param.Items.Format, param.Items.CommonValidations); err != nil {
 				return
 			}
 			if itemValidation.String != nil {
@@ -334,20 +638,29 @@ func createParamData(location string, params map[string]*spec.Parameter) (data [
 			if err = checkUnsupportedParamValidation(param.CommonValidations, []string{"minItems", "maxItems", "uniqueItems"}); err != nil {
 				return
 			}
-			if err = checkUnsupportedParamValidation(param.Items.CommonValidations, []string{"minLength", "maxLength", "enum"}); err != nil {
+			if err = checkUnsupportedParamValidation(param.Items.CommonValidations, []string{"minLength", "maxLength", "pattern", "enum"}); err != nil {
 				return
 			}
 
 			hasValidation = hasValidation || pData.Validation.String != nil || pData.ItemValidation != nil
+
+			// the items of an enum array get a type of their own, like the items of enum array properties
+			if err = setParamEnum(&pData, enumName+"Item", itemValidation, param.Items.Extensions); err != nil {
+				return
+			}
 		}
 
+		pData.HasValidation = hasValidation
+		hasValidation = routeHasValidation || hasValidation
+
 		data = append(data, pData)
 	}
 
 	return
 }
 
-func createResultType(responses *spec.Responses, readOnlyTypes map[string]bool) (resultType string, isResultSlice bool, readOnlyResult bool, resultErrors []errorData, err error) {
+// swaggerResponses are the responses of the spec, which responses can reference
+func createResultType(responses *spec.Responses, swaggerResponses map[string]spec.Response, readOnlyTypes map[string]bool) (resultType string, isResultSlice bool, readOnlyResult bool, resultErrors []errorData, err error) {
 	defer restoreLogger(logger)
 
 	hasSuccessResponse := false
@@ -362,6 +675,10 @@ func createResultType(responses *spec.Responses, readOnlyTypes map[string]bool)
 	for code, response := range responses.ResponsesProps.StatusCodeResponses {
 		logger = logger.WithField("responseCode", code)
 
+		if response, err = resolveResponse(response, swaggerResponses); err != nil {
+			return
+		}
+
 		if code >= 200 && code < 300 {
 			if hasSuccessResponse {
 				err = errors.New("Only one success response is supported")
@@ -414,7 +731,18 @@ func createResultType(responses *spec.Responses, readOnlyTypes map[string]bool)
 	return
 }
 
-func getParamValidation(t string, validations spec.CommonValidations) (val validation, err error) {
+// setParamEnum gives a parameter (or the items of an array parameter) with an enum a type of its own, named typeName
+func setParamEnum(pData *paramData, typeName string, val validation, extensions spec.Extensions) (err error) {
+	if pData.Enum, err = createEnumData(typeName, pData.Type, val, extensions); err != nil || pData.Enum == nil {
+		return
+	}
+
+	pData.Type = typeName
+	return
+}
+
+// format is only used for strings
+func getParamValidation(t, format string, validations spec.CommonValidations) (val validation, err error) {
 	switch t {
 	case "string":
 		stringVal := &stringValidation{}
@@ -429,7 +757,7 @@ func getParamValidation(t string, validations spec.CommonValidations) (val valid
 					return
 				}
 			}
-			stringVal.FlattenedEnum = flattenEnum(stringVal.Enum, ", ", "\"")
+			stringVal.FlattenedEnum = flattenEnum(stringVal.Enum, ", ")
 			val.String = stringVal
 		}
 		if validations.MinLength != nil {
@@ -442,6 +770,32 @@ func getParamValidation(t string, validations spec.CommonValidations) (val valid
 			stringVal.MaxLength = *validations.MaxLength
 			val.String = stringVal
 		}
+		if validations.Pattern != "" {
+			if _, err = regexp.Compile(validations.Pattern); err != nil {
+				logger.WithField("pattern", validations.Pattern).Error(err)
+				return
+			}
+			stringVal.HasPattern = true
+			stringVal.Pattern = validations.Pattern
+			val.String = stringVal
+		}
+		if validatedStringFormats[format] {
+			stringVal.Format = format
+			val.String = stringVal
+		}
+	case "int32", "int64", "float32", "float64":
+		// the rules for numbers are the same as in schemas
+		schema := spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Maximum:          validations.Maximum,
+				ExclusiveMaximum: validations.ExclusiveMaximum,
+				Minimum:          validations.Minimum,
+				ExclusiveMinimum: validations.ExclusiveMinimum,
+				MultipleOf:       validations.MultipleOf,
+				Enum:             validations.Enum,
+			},
+		}
+		val, err = getValidationForType(t, false, schema)
 	case "array":
 		arrayVal := &arrayValidation{}
 
@@ -467,6 +821,115 @@ func getParamValidation(t string, validations spec.CommonValidations) (val valid
 	return
 }
 
+// Go layouts of the date and date-time formats, as Go expressions
+var timeLayouts = map[string]string{
+	"date-time": "time.RFC3339",
+	"date":      `"2006-01-02"`,
+}
+
+// x-time-layout overrides the layout of a date or date-time parameter with a Go layout, or with
+// unix or unix-milli for timestamps in seconds or milliseconds
+// format is the description of the layout in validation errors
+func getTimeLayout(param *spec.Parameter) (layout, format string, err error) {
+	layout = timeLayouts[param.Format]
+	format = param.Format
+
+	extension, ok := getExtension(param.Extensions, "x-time-layout")
+	if !ok {
+		return
+	}
+
+	customLayout, _ := extension.(string)
+	if customLayout == "" {
+		err = errors.New("x-time-layout must be a non-empty string")
+		logger.WithField("timeLayout", extension).Error(err)
+		return
+	}
+
+	layout = strconv.Quote(customLayout)
+	format = customLayout
+	return
+}
+
+// Parameter patterns are compiled once, in variables named after the handler and the parameter
+// Shared parameters have their own variables, named after the parameter in the spec
+func listParamPatterns(router *routerData) {
+	addPatterns := func(param *paramData) {
+		if param.Validation.String != nil && param.Validation.String.HasPattern {
+			router.Patterns = append(router.Patterns, patternData{param.RegexpName, param.Validation.String.Pattern})
+		}
+		if param.ItemValidation != nil && param.ItemValidation.HasPattern {
+			router.Patterns = append(router.Patterns, patternData{param.RegexpName, param.ItemValidation.Pattern})
+		}
+	}
+
+	for i := range router.Routes {
+		route := &router.Routes[i]
+
+		for j := range route.Params {
+			param := &route.Params[j]
+			if param.Shared != "" {
+				param.RegexpName = param.Shared + "Param"
+				continue
+			}
+
+			param.RegexpName = route.HandlerName + strings.Title(param.Name)
+			addPatterns(param)
+		}
+	}
+
+	for i := range router.SharedParams {
+		param := &router.SharedParams[i]
+		param.RegexpName = param.Shared + "Param"
+		addPatterns(param)
+	}
+}
+
+func listParamEnums(router *routerData) (err error) {
+	defer restoreLogger(logger)
+
+	// the enum of a shared parameter is listed once, instead of for every route that uses it
+	var params []paramData
+	for _, route := range router.Routes {
+		for _, param := range route.Params {
+			if param.Shared == "" {
+				params = append(params, param)
+			}
+		}
+	}
+	params = append(params, router.SharedParams...)
+
+	names := make(map[string]bool)
+	for _, param := range params {
+		if param.Enum == nil {
+			continue
+		}
+
+		if names[param.Type] {
+			err = errors.New("Enum parameters lead to the same type name")
+			logger.WithField("type", param.Type).Error(err)
+			return
+		}
+		names[param.Type] = true
+
+		router.Enums = append(router.Enums, typeData{
+			Name: param.Type,
+			Type: param.Enum.Type,
+			Enum: param.Enum,
+		})
+	}
+
+	return
+}
+
+// bit sizes for parsing integer and number parameters
+var bitSizes = map[string]int{
+	"int32":   32,
+	"int64":   64,
+	"float32": 32,
+	"float64": 64,
+}
+
 func checkUnsupportedParamValidation(validations spec.CommonValidations, allowedValidations []string) (err error) {
 	allowValdationsMap := make(map[string]struct{})
 	for _, validation := range allowedValidations {
@@ -666,6 +1129,91 @@ func mergeParams(params ...[]spec.Parameter) (m parameterMap) {
 	return
 }
 
+// resolveParams replaces references to the parameters of the spec by the parameters themselves
+// The names of the referenced parameters are stored in sharedParams by location and name, and removed when a
+// parameter of the operation overrides them
+func resolveParams(params []spec.Parameter, swaggerParams map[string]spec.Parameter, sharedParams map[string]string) (resolved []spec.Parameter, err error) {
+	defer restoreLogger(logger)
+
+	for _, param := range params {
+		if param.Ref.String() == "" {
+			delete(sharedParams, param.In+"/"+param.Name)
+			resolved = append(resolved, param)
+			continue
+		}
+
+		logger = logger.WithField("ref", param.Ref.String())
+
+		var name string
+		if name, err = getLocalRefName(param.Ref, "parameters"); err != nil {
+			return
+		}
+
+		sharedParam, ok := swaggerParams[name]
+		if !ok {
+			err = errors.New("Referenced parameter does not exist")
+			logger.Error(err)
+			return
+		}
+
+		if sharedParam.In != "body" {
+			sharedParams[sharedParam.In+"/"+sharedParam.Name] = goFormat(name)
+		}
+		resolved = append(resolved, sharedParam)
+	}
+
+	return
+}
+
+// resolveResponse returns the response of the spec that response references, or response itself
+func resolveResponse(response spec.Response, swaggerResponses map[string]spec.Response) (resolved spec.Response, err error) {
+	if response.Ref.String() == "" {
+		resolved = response
+		return
+	}
+
+	var name string
+	if name, err = getLocalRefName(response.Ref, "responses"); err != nil {
+		return
+	}
+
+	var ok bool
+	if resolved, ok = swaggerResponses[name]; !ok {
+		err = errors.New("Referenced response does not exist")
+		logger.WithField("ref", response.Ref.String()).Error(err)
+	}
+
+	return
+}
+
+// listSharedParams returns every shared parameter that routes use once
+func listSharedParams(routes []routeData) (params []paramData, err error) {
+	defer restoreLogger(logger)
+
+	sharedParams := map[string]paramData{}
+	for _, route := range routes {
+		for _, param := range route.Params {
+			if param.Shared == "" {
+				continue
+			}
+
+			if existing, ok := sharedParams[param.Shared]; ok && (existing.Location != param.Location || existing.RawName != param.RawName) {
+				err = errors.New("Shared parameters lead to the same helper name")
+				logger.WithField("parameter", param.Shared).Error(err)
+				return
+			}
+			sharedParams[param.Shared] = param
+		}
+	}
+
+	for _, param := range sharedParams {
+		params = append(params, param)
+	}
+	sort.Sort(paramByShared(params))
+
+	return
+}
+
 func lowerStart(s string) string {
 	return strings.ToLower(s[:1]) + s[1:]
 }
@@ -699,6 +1247,7 @@ func groupErrors(router *routerData) {
 
 type routeByRoute []routeData
 type paramByLocationAndName []paramData
+type paramByShared []paramData
 type errorDataByStatusCode []errorData
 type errorByType []errorTypeData
 type errorByRoute []errorRouteData
@@ -740,6 +1289,12 @@ func (a paramByLocationAndName) Less(i, j int) bool {
 	return a[i].Name < a[j].Name
 }
 
+func (a paramByShared) Len() int      { return len(a) }
+func (a paramByShared) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a paramByShared) Less(i, j int) bool {
+	return a[i].Shared < a[j].Shared
+}
+
 func (a errorDataByStatusCode) Len() int      { return len(a) }
 func (a errorDataByStatusCode) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a errorDataByStatusCode) Less(i, j int) bool {
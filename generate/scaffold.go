@@ -0,0 +1,211 @@
+package generate
+
+import (
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/fujitsueos/go-server-generator/templates"
+	"github.com/go-openapi/spec"
+)
+
+type scaffoldData struct {
+	RouterImport         string
+	ModelImport          string
+	Imports              []string
+	Routes               []scaffoldRouteData
+	BadRequestErrors     []scaffoldErrorData
+	InternalServerErrors []scaffoldErrorData
+	HasEventStream       bool
+}
+
+type scaffoldRouteData struct {
+	routeData
+
+	// expression that creates the 500 error of the route; empty when the route has no error type for 500
+	NotImplemented string
+}
+
+type scaffoldErrorData struct {
+	Type string
+	// expression that turns errs or err into Type
+	Convert string
+}
+
+// the files of the scaffold, by name in generateServer
+var scaffoldFiles = map[string]string{
+	"scaffoldHandler":          "handler.go",
+	"scaffoldErrorTransformer": "errortransformer.go",
+	"scaffoldMain":             "main.go",
+}
+
+// Scaffold generates a starting point for a new service: a handler with a method per operation that returns a
+// "not implemented" error, an ErrorTransformer and a main function that runs the server
+// routerImport and modelImport are the import paths of the router and the model
+func Scaffold(handlerWriter, errorTransformerWriter, mainWriter io.Writer, swagger *spec.Swagger, readOnlyTypes, additionalPropsTypes map[string]bool, routerImport, modelImport string) (err error) {
+	var router routerData
+	if router, err = createRouter(swagger, readOnlyTypes, additionalPropsTypes); err != nil {
+		return
+	}
+
+	var errorTypes map[string]typeData
+	if errorTypes, err = createErrorTypes(swagger.Definitions); err != nil {
+		return
+	}
+
+	scaffold := scaffoldData{
+		RouterImport:   routerImport,
+		ModelImport:    modelImport,
+		Imports:        customFormatImports(),
+		HasEventStream: router.HasEventStream,
+	}
+
+	for _, route := range router.Routes {
+		r := scaffoldRouteData{routeData: route}
+		if route.CatchAllError != nil {
+			r.NotImplemented = newErrorCall(errorTypes[*route.CatchAllError], `"Not implemented"`, `[]string{"Not implemented"}`)
+		}
+		scaffold.Routes = append(scaffold.Routes, r)
+	}
+
+	for _, t := range router.BadRequestErrors {
+		e := scaffoldErrorData{Type: t, Convert: `strings.Join(errs.Strings(), "\n")`}
+		if t != "string" {
+			e.Convert = newErrorCall(errorTypes[t], e.Convert, "errs.Strings()")
+		}
+		scaffold.BadRequestErrors = append(scaffold.BadRequestErrors, e)
+	}
+
+	for _, t := range router.InternalServerErrors {
+		e := scaffoldErrorData{Type: t, Convert: "err.Error()"}
+		if t != "string" {
+			e.Convert = newErrorCall(errorTypes[t], e.Convert, "[]string{err.Error()}")
+		}
+		scaffold.InternalServerErrors = append(scaffold.InternalServerErrors, e)
+	}
+
+	if err = templates.ScaffoldHandler.Execute(handlerWriter, scaffold); err != nil {
+		return
+	}
+	if err = templates.ScaffoldErrorTransformer.Execute(errorTransformerWriter, scaffold); err != nil {
+		return
+	}
+	err = templates.ScaffoldMain.Execute(mainWriter, scaffold)
+
+	return
+}
+
+// createErrorTypes returns the error types of the definitions by name, with their references linked
+func createErrorTypes(definitions spec.Definitions) (errorTypes map[string]typeData, err error) {
+	var types []typeData
+	for name, definition := range definitions {
+		if isError, _ := definition.Extensions.GetBool("x-error"); !isError {
+			continue
+		}
+
+		var t typeData
+		if t, _, err = createTypeData(name, definition.Description, definition); err != nil {
+			return
+		}
+		types = append(types, t)
+	}
+
+	linkReferences(types)
+
+	errorTypes = make(map[string]typeData, len(types))
+	for _, t := range types {
+		errorTypes[t.Name] = t
+	}
+
+	return
+}
+
+// newErrorCall returns a call of the constructor of an error type in the model
+// The property for the message gets message, or messages when it is an array of strings; the other properties
+// get their zero value
+func newErrorCall(t typeData, message, messages string) string {
+	props := t.Props
+	if t.Ref != nil {
+		props = t.Ref.Props
+	}
+
+	if !t.IsStruct && t.Ref == nil {
+		// errors that are not objects are created from a string
+		return "model.New" + t.Name + "(" + message + ")"
+	}
+
+	isString := func(p propsData) bool { return !p.IsSlice && p.Type == "string" }
+	isStrings := func(p propsData) bool { return p.IsSlice && p.ItemType == "string" }
+
+	// prefer a property that is called message, otherwise take the first property that can hold one
+	messageProp := -1
+	for i, p := range props {
+		if !(isString(p) || isStrings(p)) {
+			continue
+		}
+
+		name := strings.ToLower(p.JSONName)
+		if name == "message" || name == "messages" {
+			messageProp = i
+			break
+		}
+		if messageProp == -1 {
+			messageProp = i
+		}
+	}
+
+	args := make([]string, len(props))
+	for i, p := range props {
+		switch {
+		case i == messageProp && isString(p):
+			args[i] = message
+		case i == messageProp:
+			args[i] = messages
+		default:
+			args[i] = zeroValue(p)
+		}
+	}
+
+	return "model.New" + t.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// zeroValue returns the zero value of the type of a property, as a Go expression outside the model package
+func zeroValue(p propsData) string {
+	if p.IsSlice {
+		return "nil"
+	}
+
+	switch p.Type {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "int32", "int64", "uint32", "uint64", "float32", "float64":
+		return "0"
+	}
+
+	// types of the model are capitalized, and need the package name outside of it
+	if unicode.IsUpper(rune(p.Type[0])) {
+		return "*new(model." + p.Type + ")"
+	}
+
+	return "*new(" + p.Type + ")"
+}
+
+// fileExists reports whether there is a file at path
+func fileExists(path string) (exists bool, err error) {
+	if _, err = os.Stat(path); err == nil {
+		exists = true
+		return
+	}
+
+	if os.IsNotExist(err) {
+		err = nil
+		return
+	}
+
+	logger.WithField("file", path).Error(err)
+
+	return
+}
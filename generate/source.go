@@ -0,0 +1,201 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/tools/imports"
+)
+
+// formatSource formats generated code and adds and removes its imports, like goimports does
+// Code that doesn't parse is a bug in a template, so the offending line is logged
+func formatSource(path string, src []byte) (formatted []byte, err error) {
+	var formatErr error
+	if formatted, formatErr = imports.Process(path, src, &imports.Options{Comments: true, TabIndent: true, TabWidth: 8}); formatErr != nil {
+		err = sourceError("Could not format generated code", path, src, formatErr)
+	}
+
+	return
+}
+
+// checkSource parses formatted code, to make sure that only valid Go files are written
+func checkSource(path string, src []byte) (err error) {
+	if _, parseErr := parser.ParseFile(token.NewFileSet(), path, src, parser.AllErrors); parseErr != nil {
+		err = sourceError("Generated code is not valid Go", path, src, parseErr)
+	}
+
+	return
+}
+
+// sourceError logs the line of src that causes sourceErr and returns an error with message
+func sourceError(message, path string, src []byte, sourceErr error) (err error) {
+	fields := log.Fields{
+		"file":  path,
+		"error": sourceErr,
+	}
+	if errorList, ok := sourceErr.(scanner.ErrorList); ok && len(errorList) > 0 {
+		line := errorList[0].Pos.Line
+		fields["line"] = line
+		fields["source"] = sourceLine(src, line)
+	}
+
+	err = errors.New(message)
+	logger.WithFields(fields).Error(err)
+	return
+}
+
+// sourceLine returns line number line (1-based) of src
+func sourceLine(src []byte, line int) string {
+	lines := bytes.Split(src, []byte("\n"))
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return string(bytes.TrimSpace(lines[line-1]))
+}
+
+// writeFiles writes the generated files by path, all or nothing: every file is first written to a
+// temporary file in the same folder, and only when that succeeded for all of them they are renamed
+// When a rename fails, the files that were already replaced get their previous contents back
+func writeFiles(files map[string][]byte) (err error) {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	tempPaths := make(map[string]string, len(paths))
+	defer func() {
+		// only left over when something failed
+		for _, tempPath := range tempPaths {
+			if removeErr := os.Remove(tempPath); removeErr != nil {
+				logger.WithField("file", tempPath).Error(removeErr)
+			}
+		}
+	}()
+
+	for _, path := range paths {
+		// the temp file can exist even when writing it failed, and then still needs to be removed
+		tempPath, writeErr := writeTempFile(path, files[path])
+		if tempPath != "" {
+			tempPaths[path] = tempPath
+		}
+		if err = writeErr; err != nil {
+			return
+		}
+	}
+
+	var previous map[string][]byte
+	if previous, err = readFiles(paths); err != nil {
+		return
+	}
+
+	// only the files that were replaced are restored when renaming fails
+	var replaced []string
+	for _, path := range paths {
+		if err = os.Rename(tempPaths[path], path); err != nil {
+			logger.WithField("file", path).Error(err)
+			restoreFiles(replaced, previous)
+			return
+		}
+		delete(tempPaths, path)
+		replaced = append(replaced, path)
+	}
+
+	for _, path := range paths {
+		logger.WithField("file", path).Info("Wrote file")
+	}
+
+	return
+}
+
+// readFiles returns the contents of the files that exist, by path
+func readFiles(paths []string) (contents map[string][]byte, err error) {
+	contents = make(map[string][]byte)
+
+	for _, path := range paths {
+		var data []byte
+		if data, err = ioutil.ReadFile(path); err == nil {
+			contents[path] = data
+		} else if os.IsNotExist(err) {
+			err = nil
+		} else {
+			logger.WithField("file", path).Error(err)
+			return
+		}
+	}
+
+	return
+}
+
+// restoreFiles gives the files their previous contents back, and removes the files that didn't exist before
+// It is best effort: failures are logged, as the error that caused the restore is the one that is returned
+func restoreFiles(paths []string, previous map[string][]byte) {
+	for _, path := range paths {
+		data, existed := previous[path]
+		if !existed {
+			if err := os.Remove(path); err != nil {
+				logger.WithField("file", path).Error(err)
+			}
+			continue
+		}
+
+		tempPath, err := writeTempFile(path, data)
+		if err == nil {
+			err = os.Rename(tempPath, path)
+		}
+		if err != nil {
+			logger.WithFields(log.Fields{
+				"file":  path,
+				"error": err,
+			}).Error("Could not restore file")
+
+			if tempPath != "" {
+				// left over when the rename failed
+				_ = os.Remove(tempPath)
+			}
+		}
+	}
+}
+
+func writeTempFile(path string, data []byte) (tempPath string, err error) {
+	defer restoreLogger(logger)
+	logger = logger.WithField("file", path)
+
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		logger.Error(err)
+		return
+	}
+
+	var file *os.File
+	if file, err = ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp"); err != nil {
+		logger.Error(err)
+		return
+	}
+	tempPath = file.Name()
+
+	if _, err = file.Write(data); err != nil {
+		// the write error is the one that matters
+		_ = file.Close()
+		logger.Error(err)
+		return
+	}
+	if err = file.Close(); err != nil {
+		logger.Error(err)
+		return
+	}
+
+	// TempFile creates files that only the owner can read
+	if err = os.Chmod(tempPath, 0644); err != nil {
+		logger.Error(err)
+	}
+
+	return
+}
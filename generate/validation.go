@@ -3,6 +3,8 @@ package generate
 import (
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 
 	"github.com/go-openapi/spec"
@@ -17,10 +19,15 @@ type validation struct {
 	Int    *intValidation
 	Number *numberValidation
 	String *stringValidation
+	Custom *customValidation
 }
 
 type objectValidation struct {
-	Required []string
+	Required         []string
+	HasMinProperties bool
+	MinProperties    int64
+	HasMaxProperties bool
+	MaxProperties    int64
 }
 
 type arrayValidation struct {
@@ -40,6 +47,8 @@ type intValidation struct {
 	HasMinimum       bool
 	ExclusiveMinimum bool
 	Minimum          int64
+	HasMultipleOf    bool
+	MultipleOf       int64
 }
 
 type numberValidation struct {
@@ -51,6 +60,8 @@ type numberValidation struct {
 	HasMinimum       bool
 	ExclusiveMinimum bool
 	Minimum          float64
+	HasMultipleOf    bool
+	MultipleOf       float64
 }
 
 type stringValidation struct {
@@ -62,6 +73,15 @@ type stringValidation struct {
 	MinLength     int64
 	HasPattern    bool
 	Pattern       string
+	// name of the format in the strfmt registry, empty if the format is not validated
+	Format string
+}
+
+// a value with a custom format is validated by the function of that format
+type customValidation struct {
+	Format   string
+	Validate string
+	Parse    string
 }
 
 func getValidationForType(t string, isSlice bool, schema spec.Schema) (val validation, err error) {
@@ -82,13 +102,24 @@ func getValidationForType(t string, isSlice bool, schema spec.Schema) (val valid
 			arrayVal.UniqueItems = true
 			val.Array = arrayVal
 		}
-		err = checkUnsupportedFields(t, schema, []string{"items", "minItems", "maxItems", "uniqueItems"})
+		err = checkUnsupportedFields(t, schema, []string{"items", "minItems", "maxItems", "uniqueItems", "extensions"})
+
+		return
+	}
+
+	if format, ok := customFormats[schema.Format]; ok && schema.Type.Contains("string") {
+		val.Custom = &customValidation{
+			Format:   schema.Format,
+			Validate: format.Validate,
+			Parse:    format.Parse,
+		}
+		err = checkUnsupportedFields(t, schema, []string{"format", "readOnly", "extensions"})
 
 		return
 	}
 
 	switch t {
-	case "int64":
+	case "int32", "int64", "uint32", "uint64":
 		intVal := &intValidation{}
 
 		if schema.Enum != nil {
@@ -108,7 +139,7 @@ func getValidationForType(t string, isSlice bool, schema spec.Schema) (val valid
 					return
 				}
 			}
-			intVal.FlattenedEnum = flattenEnum(intVal.Enum, ", ", "")
+			intVal.FlattenedEnum = flattenEnum(intVal.Enum, ", ")
 			val.Int = intVal
 		}
 		if schema.Maximum != nil {
@@ -123,8 +154,22 @@ func getValidationForType(t string, isSlice bool, schema spec.Schema) (val valid
 			intVal.Minimum = int64(*schema.Minimum)
 			val.Int = intVal
 		}
-		err = checkUnsupportedFields(t, schema, []string{"enum", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum", "readOnly"})
-	case "float64":
+		if schema.MultipleOf != nil {
+			intVal.HasMultipleOf = true
+			intVal.MultipleOf = int64(*schema.MultipleOf)
+			val.Int = intVal
+
+			if float64(intVal.MultipleOf) != *schema.MultipleOf {
+				err = errors.New("multipleOf must be an integer for integers")
+				logger.WithField("multipleOf", *schema.MultipleOf).Error(err)
+				return
+			}
+		}
+		if err = checkRange(t, schema); err != nil {
+			return
+		}
+		err = checkUnsupportedFields(t, schema, []string{"enum", "format", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum", "multipleOf", "readOnly", "extensions"})
+	case "float32", "float64":
 		numberVal := &numberValidation{}
 
 		if schema.Enum != nil {
@@ -137,7 +182,7 @@ func getValidationForType(t string, isSlice bool, schema spec.Schema) (val valid
 					return
 				}
 			}
-			numberVal.FlattenedEnum = flattenEnum(numberVal.Enum, ", ", "")
+			numberVal.FlattenedEnum = flattenEnum(numberVal.Enum, ", ")
 			val.Number = numberVal
 		}
 		if schema.Maximum != nil {
@@ -152,7 +197,15 @@ func getValidationForType(t string, isSlice bool, schema spec.Schema) (val valid
 			numberVal.Minimum = *schema.Minimum
 			val.Number = numberVal
 		}
-		err = checkUnsupportedFields(t, schema, []string{"enum", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum", "readOnly"})
+		if schema.MultipleOf != nil {
+			numberVal.HasMultipleOf = true
+			numberVal.MultipleOf = *schema.MultipleOf
+			val.Number = numberVal
+		}
+		if err = checkRange(t, schema); err != nil {
+			return
+		}
+		err = checkUnsupportedFields(t, schema, []string{"enum", "format", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum", "multipleOf", "readOnly", "extensions"})
 	case "string":
 		stringVal := &stringValidation{}
 
@@ -166,7 +219,7 @@ func getValidationForType(t string, isSlice bool, schema spec.Schema) (val valid
 					return
 				}
 			}
-			stringVal.FlattenedEnum = flattenEnum(stringVal.Enum, ", ", "\"")
+			stringVal.FlattenedEnum = flattenEnum(stringVal.Enum, ", ")
 			val.String = stringVal
 		}
 		if schema.MinLength != nil {
@@ -184,18 +237,40 @@ func getValidationForType(t string, isSlice bool, schema spec.Schema) (val valid
 			stringVal.Pattern = schema.Pattern
 			val.String = stringVal
 		}
+		if validatedStringFormats[schema.Format] {
+			stringVal.Format = schema.Format
+			val.String = stringVal
+		}
 		err = checkUnsupportedFields(t, schema, []string{"enum", "format", "minLength", "maxLength", "readOnly", "pattern", "extensions"})
 	case "bool":
-		err = checkUnsupportedFields(t, schema, []string{"readOnly"})
-	case "time.Time":
-		err = checkUnsupportedFields(t, schema, []string{"format", "readOnly"})
+		err = checkUnsupportedFields(t, schema, []string{"readOnly", "extensions"})
+	case "time.Time", "strfmt.Date", "[]byte":
+		err = checkUnsupportedFields(t, schema, []string{"format", "readOnly", "extensions"})
 	case "struct":
+		objectVal := &objectValidation{}
+
 		if schema.Required != nil {
-			val.Object = &objectValidation{
-				Required: schema.Required,
-			}
+			objectVal.Required = schema.Required
+			val.Object = objectVal
+		}
+		if schema.MinProperties != nil {
+			objectVal.HasMinProperties = true
+			objectVal.MinProperties = *schema.MinProperties
+			val.Object = objectVal
 		}
-		err = checkUnsupportedFields(t, schema, []string{"properties", "readOnly", "required", "extensions", "additionalProperties"})
+		if schema.MaxProperties != nil {
+			objectVal.HasMaxProperties = true
+			objectVal.MaxProperties = *schema.MaxProperties
+			val.Object = objectVal
+		}
+		// additional properties are not kept when decoding, so they cannot be counted
+		if (objectVal.HasMinProperties || objectVal.HasMaxProperties) && schema.AdditionalProperties != nil &&
+			(schema.AdditionalProperties.Allows || schema.AdditionalProperties.Schema != nil) {
+			err = errors.New("minProperties and maxProperties are not supported on objects that allow additional properties")
+			logger.Error(err)
+			return
+		}
+		err = checkUnsupportedFields(t, schema, []string{"properties", "readOnly", "required", "minProperties", "maxProperties", "extensions", "additionalProperties"})
 	default:
 		err = errors.New("Unknown type")
 		logger.Error(err)
@@ -204,6 +279,46 @@ func getValidationForType(t string, isSlice bool, schema spec.Schema) (val valid
 	return
 }
 
+// ranges of the integer and number types
+// values outside of these ranges are rejected when decoding json, so validation rules must stay within them
+var intRanges = map[string][2]float64{
+	"int32":  {math.MinInt32, math.MaxInt32},
+	"int64":  {math.MinInt64, math.MaxInt64},
+	"uint32": {0, math.MaxUint32},
+	"uint64": {0, math.MaxUint64},
+}
+
+var numberRanges = map[string][2]float64{
+	"float32": {-math.MaxFloat32, math.MaxFloat32},
+	"float64": {-math.MaxFloat64, math.MaxFloat64},
+}
+
+func checkRange(t string, schema spec.Schema) (err error) {
+	bounds, ok := intRanges[t]
+	if !ok {
+		bounds = numberRanges[t]
+	}
+
+	values := []interface{}{}
+	if schema.Minimum != nil {
+		values = append(values, *schema.Minimum)
+	}
+	if schema.Maximum != nil {
+		values = append(values, *schema.Maximum)
+	}
+	values = append(values, schema.Enum...)
+
+	for _, value := range values {
+		if number, ok := value.(float64); ok && (number < bounds[0] || number > bounds[1]) {
+			err = errors.New("Validation rule is out of range for the type")
+			logger.WithField("value", value).Error(err)
+			return
+		}
+	}
+
+	return
+}
+
 func checkUnsupportedFields(schemaType string, schema spec.Schema, allowedFields []string) (err error) {
 	allowedFieldsMap := make(map[string]struct{})
 	for _, field := range allowedFields {
@@ -270,14 +385,23 @@ func (v validation) hasValidation() bool {
 		v.Array != nil ||
 		v.Int != nil ||
 		v.Number != nil ||
-		v.String != nil
+		v.String != nil ||
+		v.Custom != nil
 }
 
-// roughly the same as strings.Join, but allow quoting strings
-// ([1, 2, 3], ", ", "") --> "1, 2, 3"
-// (["a", "b", "c"], ",", "\""") --> "\"a\", \"b\", \"c\""
-func flattenEnum(enum interface{}, separator, wrapper string) string {
-	items := strings.Fields(strings.Trim(fmt.Sprint(enum), "[]"))
+// flattenEnum joins the values of an enum as Go literals
+// ([1, 2, 3], ", ") --> "1, 2, 3"
+// (["a", "b c"], ", ") --> "\"a\", \"b c\""
+func flattenEnum(enum interface{}, separator string) string {
+	var items []string
+	if values, ok := enum.([]string); ok {
+		// strings are quoted one by one, as they can contain spaces
+		for _, value := range values {
+			items = append(items, strconv.Quote(value))
+		}
+	} else {
+		items = strings.Fields(strings.Trim(fmt.Sprint(enum), "[]"))
+	}
 
-	return wrapper + strings.Join(items, wrapper+separator+wrapper) + wrapper
+	return strings.Join(items, separator)
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
@@ -9,15 +11,57 @@ import (
 )
 
 func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("Use this as: go-server-generator <swagger-file>")
+	configPath := flag.String("config", "", "JSON config file with custom string formats and the layout")
+	module := flag.String("module", "", "import path of the folder of the swagger file, when there is no go.mod")
+	check := flag.Bool("check", false, "only check that the generated code is up to date; print a diff and exit with status 1 when it is not")
+
+	// flags override the layout of the config file
+	var layout generate.Layout
+	flag.StringVar(&layout.Dir, "out", "", "output directory, relative to the swagger file (default generated)")
+	flag.StringVar(&layout.Swagger, "swagger-file", "", "Go file with the swagger spec, relative to the output directory (default swagger.go)")
+	flag.StringVar(&layout.Model, "model-dir", "", "directory of the model package, relative to the output directory (default model)")
+	flag.StringVar(&layout.Router, "router-dir", "", "directory of the router package, relative to the output directory (default router)")
+	flag.StringVar(&layout.SwaggerPackage, "swagger-package", "", "package name of the swagger file (default: the directory name)")
+	flag.StringVar(&layout.ModelPackage, "model-package", "", "package name of the model (default: the directory name)")
+	flag.StringVar(&layout.RouterPackage, "router-package", "", "package name of the router (default: the directory name)")
+	flag.StringVar(&layout.Shared, "shared-dir", "", "directory of the shared model package for definitions in other files, relative to the output directory (default: none)")
+	flag.StringVar(&layout.SharedPackage, "shared-package", "", "package name of the shared model (default: the directory name)")
+	flag.StringVar(&layout.Scaffold, "scaffold-dir", "", "directory of a main package with a starting point for the implementation, relative to the output directory; existing files are not overwritten (default: none)")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		log.Fatal("Use this as: go-server-generator [flags] <swagger-file>; see -help for the flags")
+	}
+
+	if *configPath != "" {
+		if err := generate.LoadConfig(*configPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := generate.SetLayout(layout); err != nil {
+		log.Fatal(err)
 	}
 
-	swaggerPath, err := filepath.Abs(os.Args[1])
+	generate.SetModule(*module)
+
+	swaggerPath, err := filepath.Abs(flag.Arg(0))
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if *check {
+		diff, err := generate.CheckSwagger(swaggerPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if diff != "" {
+			fmt.Print(diff)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := generate.FromSwagger(swaggerPath); err != nil {
 		log.Fatal(err)
 	}
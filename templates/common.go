@@ -40,6 +40,10 @@ func parse(name, tpl string) (t *template.Template) {
 			err := t.ExecuteTemplate(buffer, name, pipeline)
 			return buffer.String(), err
 		},
+		// escape a property name for use in a JSON pointer (RFC 6901)
+		"pointer": func(token string) string {
+			return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+		},
 		// replace ' by `: we never need ' and we are not allowed to use ` in templates...
 	}).Parse(strings.Replace(tpl, "'", "`", -1)))
 
@@ -0,0 +1,63 @@
+package templates
+
+// enum is shared by the model and router templates
+// Input: { Name, Enum, Package }; Package is the prefix of NewValidationError, e.g. model.
+const enum = `{{ define "enum" -}}
+	const (
+		{{ range .Enum.Values -}}
+			{{ .Name }} {{ $.Name }} = {{ .Value }}
+		{{ end -}}
+	)
+
+	// Values returns the allowed values of {{ .Name }}
+	func ({{ .Name }}) Values() []{{ .Name }} {
+		return []{{ .Name }}{
+			{{ range .Enum.Values -}}
+				{{ .Name }},
+			{{ end -}}
+		}
+	}
+
+	// Valid reports whether e is one of the allowed values of {{ .Name }}
+	func (e {{ .Name }}) Valid() bool {
+		switch e {
+		case {{ range $i, $value := .Enum.Values }}{{ if $i }}, {{ end }}{{ $value.Name }}{{ end }}:
+			return true
+		}
+		return false
+	}
+
+	// UnmarshalJSON accepts any value of the underlying type, so that decoding goes on
+	// Values that are not allowed are reported by Validate, with their path
+	func (e *{{ .Name }}) UnmarshalJSON(data []byte) error {
+		var value {{ .Enum.Type }}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*e = {{ .Name }}(value)
+		return nil
+	}
+
+	// UnmarshalText rejects values that are not allowed
+	func (e *{{ .Name }}) UnmarshalText(text []byte) error {
+		{{ if .Enum.Parse -}}
+			value, err := {{ .Enum.Parse }}
+			if err != nil {
+				return err
+			}
+			return e.set({{ .Name }}(value))
+		{{- else -}}
+			return e.set({{ .Name }}(text))
+		{{- end }}
+	}
+
+	// set stores value if it is allowed
+	func (e *{{ .Name }}) set(value {{ .Name }}) error {
+		if !value.Valid() {
+			return {{ .Package }}NewValidationError("", "{{ .Name }}", "enum", value.Values(), value)
+		}
+		*e = value
+		return nil
+	}
+{{ end -}}
+`
@@ -2,7 +2,7 @@ package templates
 
 // Errors is a template for the errors file
 var Errors = parse("model",
-	`package model
+	`package {{ .Package }}
 
 // This is a generated file
 // Manual changes will be overwritten
@@ -18,7 +18,7 @@ var Errors = parse("model",
 				{{ if .Description -}}
 					// {{ .Description }}
 				{{ end -}}
-				{{ .Name }} {{ if .IsSlice }}[]{{ .ItemType }}{{ else }}*{{ .Type }}{{ end }} 'json:"{{ .JSONName }}" db:"{{ .JSONName }}"'
+				{{ .Name }} {{ if .IsSlice }}[]{{ .ItemType }}{{ else }}*{{ .Type }}{{ end }} 'json:"{{ .JSONName }}{{ if .OmitEmpty }},omitempty{{ end }}" db:"{{ .JSONName }}"'
 			{{ end -}}
 		}
   {{ else if .Ref -}}
@@ -1,7 +1,7 @@
 package templates
 
 // Model is a template for the model file
-var Model = parse("model",
+var Model = parse("model", enum+
 	`{{/* Input: { Struct, ReadOnly } */ -}}
 {{ define "modelStruct" -}}
   // {{ .ReadOnly }}{{ .Struct.Name }}
@@ -10,62 +10,103 @@ var Model = parse("model",
     {{ if .ReadOnly -}}
       {{ .Struct.Name }}
     {{ end -}}
+    {{/* the read-only variant overrides the properties that reference a read-only variant */ -}}
     {{ range .Struct.Props -}}
-      {{ if eq (eq $.ReadOnly "ReadOnly") .IsReadOnly -}}
+      {{ if and $.ReadOnly .IsWriteOnly -}}
+        // {{ .Name }} hides the write-only property of {{ $.Struct.Name }}, so it is never in a response
+        {{ .Name }} *struct{} 'json:"{{ .JSONName }},omitempty" db:"-"'
+      {{ else if eq (eq $.ReadOnly "ReadOnly") (or .IsReadOnly (and (eq $.ReadOnly "ReadOnly") .HasReadOnlyType)) -}}
         {{ if .Description -}}
           // {{ .Description }}
         {{ end -}}
-        {{ .Name }} {{ if .IsSlice }}[]{{ .ItemType }}{{ else }}*{{ .Type }}{{ end }} 'json:"{{ .JSONName }}" db:"{{ .JSONName }}"'
+        {{ .Name }} {{ template "propType" dict "Prop" . "ReadOnly" $.ReadOnly }} 'json:"{{ .JSONName }}
+          {{- if .OmitEmpty }}{{ if .Nullable }},omitzero{{ else }},omitempty{{ end }}{{ end }}" db:"{{ .JSONName }}"'
       {{ end -}}
     {{ end -}}
   }
 
-  {{/* Note that (and .ReadOnly .Struct.Name) means (if .ReadOnly == "" then "" else .Struct.Name) */}}
-  {{ template "constructor" dict "Name" (printf "%s%s" .ReadOnly .Struct.Name) "NonReadOnlyName" (and .ReadOnly .Struct.Name) "Props" .Struct.Props }}
+  {{ template "constructor" dict "Name" .Struct.Name "ReadOnly" .ReadOnly "ReferenceName" "" "Props" .Struct.Props }}
 {{ end -}}
 
+{{/* Input: { Prop, ReadOnly }; like slices, []byte is nil when the property is missing */ -}}
+{{ define "propType" -}}
+  {{ $type := templateAsString "propBaseType" . -}}
+  {{ with .Prop -}}
+    {{ if .Nullable -}}
+      Optional[{{ $type }}]
+    {{- else if or .IsSlice .NonPointer (eq .Type "[]byte") -}}
+      {{ $type }}
+    {{- else -}}
+      *{{ $type }}
+    {{- end }}
+  {{- end }}
+{{- end }}
+
+{{/* Input: { Prop, ReadOnly }; the type of the property without pointer or Optional */ -}}
+{{ define "propBaseType" -}}
+  {{ with .Prop -}}
+    {{ if .IsSlice }}[]{{ end -}}
+    {{ if and $.ReadOnly .HasReadOnlyType }}ReadOnly{{ end -}}
+    {{ if .IsSlice }}{{ .ItemType }}{{ else }}{{ .Type }}{{ end -}}
+  {{ end -}}
+{{- end }}
+
+{{/* Input: prop; the constructor argument with the same name as the property */ -}}
+{{ define "propValue" -}}
+  {{ if .Nullable -}}
+    NewOptional({{ .JSONName }})
+  {{- else if or .IsSlice .NonPointer (eq .Type "[]byte") -}}
+    {{ .JSONName }}
+  {{- else -}}
+    &{{ .JSONName }}
+  {{- end }}
+{{- end }}
+
 {{/* Input: { Slice, ReadOnly } */ -}}
 {{ define "modelSlice" }}
   // {{ .ReadOnly }}{{ .Slice.Name }}{{ if .Slice.Description }} {{ .Slice.Description }}{{ else }} No description provided{{ end }}
   type {{ .ReadOnly }}{{ .Slice.Name }} []{{ .ReadOnly }}{{ .Slice.ItemType }}
 {{ end -}}
 
-{{/* Input: { Name, NonReadOnlyName, ReferenceName, Props } */ -}}
+{{/* Input: { Name, ReadOnly, ReferenceName, Props } */ -}}
+{{/* Name is the name without ReadOnly; the read-only variant takes all properties except the write-only ones */ -}}
 {{ define "constructor" }}
-  // New{{ .Name }} returns a new {{ .Name }}
-  func New{{ .Name }}(
+  // New{{ .ReadOnly }}{{ .Name }} returns a new {{ .ReadOnly }}{{ .Name }}
+  func New{{ .ReadOnly }}{{ .Name }}(
     {{- range .Props -}}
-      {{ if or $.NonReadOnlyName (not .IsReadOnly) -}}
-        {{ .JSONName }} {{ if .IsSlice }}[]{{ .ItemType }}{{ else }}{{ .Type }}{{ end }},
+      {{ if or (and $.ReadOnly (not .IsWriteOnly)) (and (not $.ReadOnly) (not .IsReadOnly)) -}}
+        {{ .JSONName }} {{ template "propBaseType" dict "Prop" . "ReadOnly" $.ReadOnly }},
       {{- end -}}
     {{ end -}}
-  ) {{ .Name }} {
+  ) {{ .ReadOnly }}{{ .Name }} {
     {{ if .ReferenceName -}}
-      return {{ .Name }}(New{{ .ReferenceName }}(
+      return {{ .ReadOnly }}{{ .Name }}(New{{ .ReadOnly }}{{ .ReferenceName }}(
         {{- range .Props -}}
-          {{ .JSONName }},
-        {{- end -}}
+          {{ if or (and $.ReadOnly (not .IsWriteOnly)) (and (not $.ReadOnly) (not .IsReadOnly)) -}}
+            {{ .JSONName }},
+          {{- end -}}
+        {{ end -}}
       ))
-    {{ else -}}
-      return {{ .Name }}{
-        {{ if $.NonReadOnlyName -}}
-          {{ .NonReadOnlyName }}: New{{ .NonReadOnlyName }}(
-            {{- range .Props -}}
-              {{ if not .IsReadOnly -}}
-                {{ .JSONName }},
-              {{- end -}}
-            {{ end -}}
-          ),
+    {{ else if .ReadOnly -}}
+      return ReadOnly{{ .Name }}{
+        {{ .Name }}: {{ .Name }}{
           {{ range .Props -}}
-            {{ if .IsReadOnly -}}
-              {{ .Name }}: {{ if not .IsSlice }}&{{ end }}{{ .JSONName }},
+            {{ if not (or .IsReadOnly .IsWriteOnly .HasReadOnlyType) -}}
+              {{ .Name }}: {{ template "propValue" . }},
             {{ end -}}
           {{ end -}}
-        {{ else -}}
-          {{ range .Props -}}
-            {{ if not .IsReadOnly -}}
-              {{ .Name }}: {{ if not .IsSlice }}&{{ end }}{{ .JSONName }},
-            {{ end -}}
+        },
+        {{ range .Props -}}
+          {{ if and (or .IsReadOnly .HasReadOnlyType) (not .IsWriteOnly) -}}
+            {{ .Name }}: {{ template "propValue" . }},
+          {{ end -}}
+        {{ end -}}
+      }
+    {{ else -}}
+      return {{ .Name }}{
+        {{ range .Props -}}
+          {{ if not .IsReadOnly -}}
+            {{ .Name }}: {{ template "propValue" . }},
           {{ end -}}
         {{ end -}}
       }
@@ -73,11 +114,75 @@ var Model = parse("model",
   }
 {{ end -}}
 
-package model
+package {{ .Package }}
 
 // This is a generated file
 // Manual changes will be overwritten
 
+{{ if .Imports -}}
+  import (
+    {{ range .Imports -}}
+      "{{ . }}"
+    {{ end -}}
+    {{ if .SharedImport -}}
+      {{ .SharedPackage }} "{{ .SharedImport }}"
+    {{ end -}}
+  )
+{{ end -}}
+
+{{ if .HasNullable -}}
+  // Optional is a property that can be missing, null or set
+  // It tells an explicit null apart from a missing property, which a pointer cannot do
+  type Optional[T any] struct {
+    // Present is true when the property is in the JSON, even when it is null
+    Present bool
+    // Null is true when the property is explicitly null
+    Null bool
+    // Value is the value of the property when it is present and not null
+    Value T
+  }
+
+  // NewOptional returns a present Optional with a value
+  func NewOptional[T any](value T) Optional[T] {
+    return Optional[T]{Present: true, Value: value}
+  }
+
+  // NewNullOptional returns a present Optional that is null
+  func NewNullOptional[T any]() Optional[T] {
+    return Optional[T]{Present: true, Null: true}
+  }
+
+  // Get returns the value and whether it is set, i.e. present and not null
+  func (o Optional[T]) Get() (value T, ok bool) {
+    return o.Value, o.Present && !o.Null
+  }
+
+  // IsZero reports whether the property is missing, for the omitzero json option
+  func (o Optional[T]) IsZero() bool {
+    return !o.Present
+  }
+
+  // MarshalJSON writes null when the property is null or missing
+  func (o Optional[T]) MarshalJSON() ([]byte, error) {
+    if !o.Present || o.Null {
+      return []byte("null"), nil
+    }
+    return json.Marshal(o.Value)
+  }
+
+  // UnmarshalJSON is only called when the property is present
+  func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+    var value T
+    o.Present = true
+    o.Null = string(data) == "null"
+    o.Value = value
+    if o.Null {
+      return nil
+    }
+    return json.Unmarshal(data, &o.Value)
+  }
+{{ end -}}
+
 {{ range .Types -}}
   {{ if .IsStruct -}}
     {{ template "modelStruct" dict "Struct" . "ReadOnly" "" }}
@@ -93,9 +198,48 @@ package model
     // {{ .Name }}{{ if .Description }} {{ .Description }}{{ else }} No description provided{{ end }}
     type {{ .Name }} {{ .Type }}
 
+    {{ if .Enum -}}
+      {{ template "enum" dict "Name" .Name "Enum" .Enum "Package" "" }}
+    {{ end -}}
+
     {{ if .Ref -}}
-      {{ template "constructor" dict "Name" .Name "ReferenceName" .Ref.Name "Props" .Ref.Props }}
+      {{ template "constructor" dict "Name" .Name "ReadOnly" "" "ReferenceName" .Ref.Name "Props" .Ref.Props }}
+    {{ end -}}
+
+    {{ if .HasReadOnlyProps -}}
+      // ReadOnly{{ .Name }} is the variant of {{ .Name }} with read-only properties, for responses
+      type ReadOnly{{ .Name }} ReadOnly{{ .Type }}
+
+      {{ if .Ref -}}
+        {{ template "constructor" dict "Name" .Name "ReadOnly" "ReadOnly" "ReferenceName" .Ref.Name "Props" .Ref.Props }}
+      {{ end -}}
     {{ end -}}
   {{ end -}}
 {{ end }}
+
+{{/* the types of the shared model package are aliased, so the model has the same types with or without it */ -}}
+{{ range .SharedTypes -}}
+  // {{ .Name }} is defined in the shared model package
+  type {{ .Name }} = {{ $.SharedPackage }}.{{ .Name }}
+  {{ if .HasReadOnlyProps -}}
+    type ReadOnly{{ .Name }} = {{ $.SharedPackage }}.ReadOnly{{ .Name }}
+  {{ end }}
+
+  {{ if or .IsStruct (and (not .IsSlice) .Ref) -}}
+    // New{{ .Name }} returns a new {{ .Name }}
+    var New{{ .Name }} = {{ $.SharedPackage }}.New{{ .Name }}
+    {{ if .HasReadOnlyProps -}}
+      // NewReadOnly{{ .Name }} returns a new ReadOnly{{ .Name }}
+      var NewReadOnly{{ .Name }} = {{ $.SharedPackage }}.NewReadOnly{{ .Name }}
+    {{ end }}
+  {{ end -}}
+
+  {{ with .Enum -}}
+    const (
+      {{ range .Values -}}
+        {{ .Name }} = {{ $.SharedPackage }}.{{ .Name }}
+      {{ end -}}
+    )
+  {{ end -}}
+{{ end }}
 `)
@@ -2,7 +2,7 @@ package templates
 
 // RouteErrors is a template for the error types of each route
 var RouteErrors = parse("routeErrors", `
-package model
+package {{ .ModelPackageName }}
 
 // This is a generated file
 // Manual changes will be overwritten
@@ -1,7 +1,7 @@
 package templates
 
 // Router is a template for the router file
-var Router = parse("router",
+var Router = parse("router", enum+
 	`{{ define "getParam" -}}
 	{{- if eq . "path" -}}
 		params.ByName
@@ -17,7 +17,214 @@ var Router = parse("router",
 	respondJSON(w, m.errorTransformer.ErrorTo{{ if . }}{{ . }}{{ else }}String{{ end }}(err), "{{ if . }}{{ . }}{{ else }}string{{ end }}", http.StatusInternalServerError, errorTransformer)
 {{ end -}}
 
-package router
+{{/* Input: { Param, Value } */}}
+{{ define "timeParamError" -}}
+	log.WithFields(log.Fields{
+		"field": "{{ .Param.RawName }}",
+		"value": {{ .Value }},
+		"error": err,
+	}).Error("Failed to parse time")
+	errs = append(errs, newParamError("{{ .Param.Location }}", "/{{ pointer .Param.RawName }}", "{{ .Param.RawName }}", "format", {{ printf "%q" .Param.TimeFormat }}, {{ .Value }}))
+{{ end -}}
+
+{{/* Input: param */}}
+{{ define "validateNumberParam" -}}
+	{{ with or .Validation.Int .Validation.Number -}}
+		{{ if .Enum -}}
+			switch {{ $.Name }} {
+			case {{ .FlattenedEnum }}: // ok
+			default:
+				errs = append(errs, newParamError("{{ $.Location }}", "/{{ pointer $.RawName }}", "{{ $.RawName }}", "enum", []{{ $.Type }}{ {{ .FlattenedEnum }} }, {{ $.Name }}))
+			}
+		{{ end -}}
+		{{ if .HasMaximum -}}
+			if {{ $.Name }} {{ if .ExclusiveMaximum }}>={{ else }}>{{ end }} {{ .Maximum }} {
+				errs = append(errs, newParamError("{{ $.Location }}", "/{{ pointer $.RawName }}", "{{ $.RawName }}", "{{ if .ExclusiveMaximum }}exclusiveMaximum{{ else }}maximum{{ end }}", {{ .Maximum }}, {{ $.Name }}))
+			}
+		{{ end -}}
+		{{ if .HasMinimum -}}
+			if {{ $.Name }} {{ if .ExclusiveMinimum }}<={{ else }}<{{ end }} {{ .Minimum }} {
+				errs = append(errs, newParamError("{{ $.Location }}", "/{{ pointer $.RawName }}", "{{ $.RawName }}", "{{ if .ExclusiveMinimum }}exclusiveMinimum{{ else }}minimum{{ end }}", {{ .Minimum }}, {{ $.Name }}))
+			}
+		{{ end -}}
+		{{ if .HasMultipleOf -}}
+			if {{ if eq $.Type "float32" "float64" }}!isMultipleOf({{ if eq $.Type "float32" }}float64({{ $.Name }}){{ else }}{{ $.Name }}{{ end }}, {{ .MultipleOf }}){{ else }}{{ $.Name }} % {{ .MultipleOf }} != 0{{ end }} {
+				errs = append(errs, newParamError("{{ $.Location }}", "/{{ pointer $.RawName }}", "{{ $.RawName }}", "multipleOf", {{ .MultipleOf }}, {{ $.Name }}))
+			}
+		{{ end -}}
+	{{ end -}}
+{{ end -}}
+
+{{/* Input: param; an else branch for a missing parameter, which is an error when it is required */}}
+{{ define "missingParam" -}}
+	{{- if and .Required .HasValidation }} else {
+		errs = append(errs, newParamError("{{ .Location }}", "/{{ pointer .RawName }}", "{{ .RawName }}", "required", nil, nil))
+	}
+	{{- end }}
+{{- end }}
+
+{{/* Input: param; declares a variable named after the param, and appends to errs */}}
+{{/* A missing parameter is not validated, except that it is reported when it is required */}}
+{{ define "parseParam" -}}
+	{{ if .IsTime -}}
+		{{ if .Required -}}
+			var {{ .Name }} time.Time
+			if value := {{ template "getParam" .Location }}("{{ .RawName }}"); value != "" {
+				var err error
+				if {{ .Name }}, err = parseTime(value, {{ .TimeLayout }}); err != nil {
+					{{ template "timeParamError" dict "Param" . "Value" "value" -}}
+				}
+			}{{ template "missingParam" . }}
+		{{ else -}}
+			// a missing optional parameter is nil
+			var {{ .Name }} *time.Time
+			if value := {{ template "getParam" .Location }}("{{ .RawName }}"); value != "" {
+				if parsed, err := parseTime(value, {{ .TimeLayout }}); err != nil {
+					{{ template "timeParamError" dict "Param" . "Value" "value" -}}
+				} else {
+					{{ .Name }} = &parsed
+				}
+			}
+		{{ end -}}
+	{{ else if .Validation.Custom -}}
+		var {{ .Name }} {{ .Type }}
+		if value := {{ template "getParam" .Location }}("{{ .RawName }}"); value != "" {
+			var err error
+			{{ if .Validation.Custom.Parse -}}
+				if {{ .Name }}, err = {{ .Validation.Custom.Parse }}(value); err == nil {
+					err = {{ .Validation.Custom.Validate }}({{ .Name }})
+				}
+			{{- else -}}
+				{{ .Name }} = {{ .Type }}(value)
+				err = {{ .Validation.Custom.Validate }}({{ .Name }})
+			{{- end }}
+			if err != nil {
+				log.WithFields(log.Fields{
+					"field": "{{ .RawName }}",
+					"value": value,
+					"error": err,
+				}).Error("Invalid {{ .Validation.Custom.Format }}")
+				errs = append(errs, newParamError("{{ .Location }}", "/{{ pointer .RawName }}", "{{ .RawName }}", "format", "{{ .Validation.Custom.Format }}", value))
+			}
+		}{{ template "missingParam" . }}
+	{{ else if .IsNumber -}}
+		// a missing parameter is left at zero
+		var {{ .Name }} {{ .Type }}
+		if value := {{ template "getParam" .Location }}("{{ .RawName }}"); value != "" {
+			{{ if eq .Type "float32" "float64" -}}
+				parsed, err := strconv.ParseFloat(value, {{ .BitSize }})
+			{{- else -}}
+				parsed, err := strconv.ParseInt(value, 10, {{ .BitSize }})
+			{{- end }}
+			if err != nil {
+				errs = append(errs, newParamError("{{ .Location }}", "/{{ pointer .RawName }}", "{{ .RawName }}", "type", "{{ if .Enum }}{{ .Enum.Type }}{{ else }}{{ .Type }}{{ end }}", value))
+			} else {
+				{{ .Name }} = {{ if eq .Type "int64" "float64" }}parsed{{ else }}{{ .Type }}(parsed){{ end }}
+				{{ template "validateNumberParam" . -}}
+			}
+		}{{ template "missingParam" . }}
+	{{ else if .IsArray -}}
+		{{ $values := .Name }}{{ if .Enum }}{{ $values = printf "%sValues" .Name }}{{ end -}}
+		{{ $values }} := parseArray({{ template "getParam" .Location }}("{{ .RawName }}"))
+		{{ if or .Validation.Array .ItemValidation -}}
+			if len({{ $values }}) > 0 {
+				{{ if .Validation.Array -}}
+					errs = append(errs, validateArray({{ $values }}, "{{ .Location }}", "/{{ pointer .RawName }}", "{{ .RawName }}",
+						{{- if .Validation.Array.HasMinItems -}} {{ .Validation.Array.MinItems }} {{- else -}} -1 {{- end -}},
+						{{- if .Validation.Array.HasMaxItems -}} {{ .Validation.Array.MaxItems }} {{- else -}} -1 {{- end -}},
+						{{- .Validation.Array.UniqueItems -}}
+					)...)
+				{{ end -}}
+				{{ if .ItemValidation -}}
+					for i := range {{ $values }} {
+						errs = append(errs, validateString({{ $values }}[i], "{{ .Location }}", fmt.Sprintf("/{{ pointer .RawName }}/%d", i), fmt.Sprintf("{{ .RawName }}[%d]", i),
+							{{- if .ItemValidation.HasMinLength -}} {{ .ItemValidation.MinLength }} {{- else -}} -1 {{- end -}},
+							{{- if .ItemValidation.HasMaxLength -}} {{ .ItemValidation.MaxLength }} {{- else -}} -1 {{- end -}},
+							{{- if .ItemValidation.Enum -}} []string{ {{ .ItemValidation.FlattenedEnum }} } {{- else -}} nil {{- end -}},
+							{{- if .ItemValidation.HasPattern -}} regexp{{ .RegexpName }} {{- else -}} nil {{- end -}},
+							{{- printf "%q" .ItemValidation.Format -}}
+						)...)
+					}
+				{{ end -}}
+			}{{ template "missingParam" . }}
+		{{ end -}}
+		{{ if .Enum -}}
+			{{ .Name }} := make([]{{ .Type }}, len({{ $values }}))
+			for i := range {{ $values }} {
+				{{ .Name }}[i] = {{ .Type }}({{ $values }}[i])
+			}
+		{{ end -}}
+	{{ else -}}
+		{{ if .Enum -}}
+			{{ .Name }} := {{ .Type }}({{ template "getParam" .Location }}("{{ .RawName }}"))
+		{{- else -}}
+			{{ .Name }} := {{ template "getParam" .Location }}("{{ .RawName }}")
+		{{- end }}
+		{{ if .Validation.String -}}
+			if {{ .Name }} != "" {
+				errs = append(errs, validateString({{ if .Enum }}string({{ .Name }}){{ else }}{{ .Name }}{{ end }}, "{{ .Location }}", "/{{ pointer .RawName }}", "{{ .RawName }}",
+					{{- if .Validation.String.HasMinLength -}} {{ .Validation.String.MinLength }} {{- else -}} -1 {{- end -}},
+					{{- if .Validation.String.HasMaxLength -}} {{ .Validation.String.MaxLength }} {{- else -}} -1 {{- end -}},
+					{{- if .Validation.String.Enum -}} []string{ {{ .Validation.String.FlattenedEnum }} } {{- else -}} nil {{- end -}},
+					{{- if .Validation.String.HasPattern -}} regexp{{ .RegexpName }} {{- else -}} nil {{- end -}},
+					{{- printf "%q" .Validation.String.Format -}}
+				)...)
+			}{{ template "missingParam" . }}
+		{{ end -}}
+	{{ end }}
+{{ end -}}
+
+{{/* Input: location */}}
+{{ define "paramSource" -}}
+	{{- if eq . "path" -}}
+		params
+	{{- else if eq . "header" -}}
+		r
+	{{- else -}}
+		query
+	{{- end -}}
+{{ end -}}
+
+{{/* Input: route */}}
+{{ define "handlerMethod" -}}
+	{{ .HandlerName }}(ctx context.Context,
+		{{- range .Params -}}
+			{{ .Name }} {{ if .IsArray }}[]{{ end }}{{ .Type }},
+		{{- end -}}
+		{{- if .Body -}}
+			{{ .Body.Name }} model.{{ .Body.Type }},
+		{{- end -}}
+		{{- if .EventType -}}
+			sender {{ .HandlerName }}Sender
+		{{- end -}}
+	) (
+		{{- if .ResultType -}}
+			{{ if .IsResultSlice }}[]{{ end }}model.{{ if .ReadOnlyResult }}ReadOnly{{ end }}{{ .ResultType }},
+		{{- end -}}
+		model.{{ .HandlerName }}Error)
+{{- end -}}
+
+{{/* Input: route */}}
+{{ define "eventSender" -}}
+	type {{ .Name }}Sender struct {
+		stream *eventStream
+	}
+
+	func (s *{{ .Name }}Sender) Send(event model.{{ if .ReadOnlyEvent }}ReadOnly{{ end }}{{ .EventType }}) error {
+		if errs := event.Validate(); len(errs) > 0 {
+			err := errors.New("Invalid event data")
+			log.WithFields(log.Fields{
+				"dataType": "{{ if .ReadOnlyEvent }}ReadOnly{{ end }}{{ .EventType }}",
+				"error": strings.Join(errs.Strings(), "\n"),
+			}).Error(err)
+			return err
+		}
+
+		return s.stream.send("", event)
+	}
+{{ end -}}
+
+package {{ .Package }}
 
 // This is a generated file
 // Manual changes will be overwritten
@@ -30,34 +237,58 @@ import (
 	"github.com/julienschmidt/httprouter"
 	log "github.com/sirupsen/logrus"
 
-	"{{ .ModelPackage }}"
+	model "{{ .ModelPackage }}"
+	{{ range .Imports -}}
+		"{{ . }}"
+	{{ end -}}
 )
 
+{{ if .TagHandlers -}}
+// Handler implements the actual functionality of the service
+// It is composed of an interface per tag, so that each tag can be implemented separately
+type Handler interface {
+	{{ range .TagHandlers -}}
+		{{ .Name }}
+	{{ end -}}
+}
+
+{{ range $handler := .TagHandlers -}}
+	// {{ .Name }} implements the operations with tag {{ .Tag }}
+	type {{ .Name }} interface {
+		{{ range $.Routes -}}
+			{{ if eq .Tag $handler.Tag -}}
+				{{ template "handlerMethod" . }}
+			{{ end -}}
+		{{ end -}}
+	}
+
+{{ end -}}
+{{ else -}}
 // Handler implements the actual functionality of the service
 type Handler interface {
 {{ range .Routes -}}
 	{{ if .NewBlock -}}
 		// {{ .Tag }}
 	{{ end -}}
-	{{ .HandlerName }}(ctx context.Context,
-		{{- range .Params -}}
-			{{ .Name }} {{ if .IsArray }}[]{{ end }}{{ .Type }},
-		{{- end -}}
-		{{- if .Body -}}
-			{{ .Body.Name }} model.{{ .Body.Type }}
-		{{- end -}}
-	) (
-		{{- if .ResultType -}}
-			{{ if .IsResultSlice }}[]{{ end }}model.{{ if .ReadOnlyResult }}ReadOnly{{ end }}{{ .ResultType }},
-		{{- end -}}
-		model.{{ .HandlerName }}Error)
+	{{ template "handlerMethod" . }}
 {{ end }}
 }
+{{ end }}
+{{ range .Routes -}}
+	{{ if .EventType -}}
+		// {{ .HandlerName }}Sender sends events to the client of {{ .HandlerName }}
+		// Send fails when the event is invalid, or when the client has disconnected
+		type {{ .HandlerName }}Sender interface {
+			Send(event model.{{ if .ReadOnlyEvent }}ReadOnly{{ end }}{{ .EventType }}) error
+		}
+
+	{{ end -}}
+{{ end -}}
 
 // ErrorTransformer transforms errors in standard format into the format according to the swagger spec
 type ErrorTransformer interface {
 	{{ range .BadRequestErrors -}}
-		ValidationErrorsTo{{ if eq "string" . }}String{{ else }}{{ . }}{{ end }}(errs []string) {{ if eq "string" . }}String{{ else }}model.{{ . }}{{ end }}
+		ValidationErrorsTo{{ if eq "string" . }}String{{ else }}{{ . }}{{ end }}(errs model.ValidationErrors) {{ if eq "string" . }}string{{ else }}model.{{ . }}{{ end }}
 	{{ end -}}
 	{{ range .InternalServerErrors -}}
 		ErrorTo{{ if eq "string" . }}String{{ else }}{{ . }}{{ end }}(err error) {{ if eq "string" . }}string{{ else }}model.{{ . }}{{ end }}
@@ -110,61 +341,42 @@ func (m *middleware) {{ .Name }}(w http.ResponseWriter, r *http.Request, {{ if .
 		var result {{ if .IsResultSlice }}[]{{ end }}model.{{ if .ReadOnlyResult }}ReadOnly{{ end }}{{ .ResultType }}
 	{{ end -}}
 	{{ if or .ResultType .HasValidation -}}
-		var errs []string
+		var errs model.ValidationErrors
 	{{ end -}}
 
 	{{ if .HasQueryParams -}}
 		query := r.URL.Query()
 	{{ end -}}
 	{{ range .Params -}}
-		{{ if eq .Type "time.Time" -}}
-			if {{ .Name }}, err := time.Parse(time.RFC3339, {{ template "getParam" .Location }}("{{ .RawName }}")); err != nil {
-				log.WithFields(log.Fields{
-					"field": "{{ .RawName }}",
-					"value": {{ template "getParam" .Location }}("{{ .RawName }}"),
-				}).Error("Failed to parse time")
-				errs = append(errs, "Failed to parse {{ .RawName }} as time")
-			}
-		{{ else if .IsArray -}}
-			{{ .Name }} := parseArray({{ template "getParam" .Location }}("{{ .RawName }}"))
-			{{ if .Validation.Array -}}
-				errs = append(errs, validateArray({{ .Name }}, "{{ .RawName }}",
-					{{- if .Validation.Array.HasMinItems -}} {{ .Validation.Array.MinItems }} {{- else -}} nil {{- end -}},
-					{{- if .Validation.Array.HasMaxItems -}} {{ .Validation.Array.MaxItems }} {{- else -}} nil {{- end -}},
-					{{- .Validation.Array.UniqueItems -}}
-				)...)
-			{{ end -}}
-			{{ if .ItemValidation -}}
-				for i := range {{ .Name }} {
-					errs = append(errs, validateString({{ .Name }}[i], fmt.Sprintf("{{ .RawName }}[%d]", i),
-						{{- if .ItemValidation.HasMinLength -}} {{ .ItemValidation.MinLength }} {{- else -}} nil {{- end -}},
-						{{- if .ItemValidation.HasMaxLength -}} {{ .ItemValidation.MaxLength }} {{- else -}} nil {{- end -}},
-						{{- if .ItemValidation.Enum -}} []string{ {{ .ItemValidation.FlattenedEnum }} } {{- else -}} nil {{- end -}}
-					)...)
-				}
-			{{ end -}}
+		{{ if .Shared -}}
+			{{ if .HasValidation -}}
+				{{ .Name }}, paramErrs := parse{{ .Shared }}Param({{ template "paramSource" .Location }})
+				errs = append(errs, paramErrs...)
+			{{- else -}}
+				{{ .Name }} := parse{{ .Shared }}Param({{ template "paramSource" .Location }})
+			{{- end }}
 		{{ else -}}
-			{{ .Name }} := {{ template "getParam" .Location }}("{{ .RawName }}")
-			{{ if .Validation.String -}}
-				errs = append(errs, validateString({{ .Name }}, "{{ .RawName }}",
-					{{- if .Validation.String.HasMinLength -}} {{ .Validation.String.MinLength }} {{- else -}} nil {{- end -}},
-					{{- if .Validation.String.HasMaxLength -}} {{ .Validation.String.MaxLength }} {{- else -}} nil {{- end -}},
-					{{- if .Validation.String.Enum -}} []string{ {{ .Validation.String.FlattenedEnum }} } {{- else -}} nil {{- end -}}
-				)...)
-			{{ end -}}
-		{{ end }}
+			{{ template "parseParam" . }}
+		{{- end }}
 	{{ end -}}
 
 	{{ if .Body -}}
 		var {{ .Body.Name }} model.{{ .Body.Type }}
-		if err := json.NewDecoder(r.Body).Decode(&{{ .Body.Name }}); err != nil {
-			errs = append(errs, err.Error())
+		if err := decodeBody(w, r, &{{ .Body.Name }}, {{ .Body.MaxSize }}, {{ .Body.DisallowUnknownFields }}, {{ .Body.Strict }}); {{ if .Body.MaxSize }}err == errBodyTooLarge {
+			log.WithFields(log.Fields{
+				"bodyType": "{{ .Body.Type }}",
+				"maxSize": {{ .Body.MaxSize }},
+			}).Error(err)
+			respondJSON(w, m.errorTransformer.ErrorTo{{ if .CatchAllError }}{{ .CatchAllError }}{{ else }}String{{ end }}(err), "{{ if .CatchAllError }}{{ .CatchAllError }}{{ else }}string{{ end }}", http.StatusRequestEntityTooLarge, errorTransformer)
+			return
+		} else if {{ end }}err != nil {
+			errs = append(errs, bodyValidationError(err))
 			log.WithFields(log.Fields{
 				"bodyType": "{{ .Body.Type }}",
 				"error": err,
 			}).Error("Failed to parse body data")
 		} else if e := {{ .Body.Name }}.Validate(); len(e) > 0 {
-			errs = append(errs, e...)
+			errs = append(errs, e.WithLocation("body")...)
 		}
 	{{ end -}}
 
@@ -172,7 +384,7 @@ func (m *middleware) {{ .Name }}(w http.ResponseWriter, r *http.Request, {{ if .
 		if len(errs) > 0 {
 			log.WithFields(log.Fields{
 				"handler": "{{ .Name }}",
-				"errs": strings.Join(errs, "\n"),
+				"errs": strings.Join(errs.Strings(), "\n"),
 			})
 			respondJSON(w, m.errorTransformer.ValidationErrorsTo{{ if .ValidationError }}{{ .ValidationError }}{{ else }}String{{ end }}(errs), "{{ if .ValidationError }}{{ .ValidationError }}{{ else }}string{{ end }}", http.StatusBadRequest, errorTransformer)
 			return
@@ -180,6 +392,44 @@ func (m *middleware) {{ .Name }}(w http.ResponseWriter, r *http.Request, {{ if .
 
 	{{ end -}}
 
+	{{ if .EventType -}}
+		stream, err := newEventStream(r.Context(), w)
+		if err != nil {
+			log.WithField("error", err).Error("Failed to start event stream")
+			{{ template "unexpectedError" .CatchAllError -}}
+			return
+		}
+		defer func() {
+			// the status code has already been sent, so a panic is reported as an event
+			if recovered := recover(); recovered != nil {
+				m.reportPanic(recovered)
+				err := errors.New("Recovered")
+				log.WithField("error", recovered).Error(err)
+				if err := stream.send("error", errorTransformer(err)); err != nil {
+					log.WithField("error", err).Error("Failed to send error event")
+				}
+			}
+			stream.close()
+		}()
+
+		if handlerError := m.handler.{{ .HandlerName }}(r.Context(),
+			{{- range .Params -}}
+				{{ .Name }},
+			{{- end -}}
+			{{- if .Body -}}
+				{{ .Body.Name }},
+			{{- end -}}
+			&{{ .Name }}Sender{stream}); handlerError != nil {
+			// the status code has already been sent, so the error is reported as an event
+			errorType, _ := handlerError.{{ .HandlerName }}StatusCode()
+			if err := stream.send("error", handlerError); err != nil {
+				log.WithFields(log.Fields{
+					"dataType": errorType,
+					"error": err,
+				}).Error("Failed to send error event")
+			}
+		}
+	{{- else -}}
 	var handlerError model.{{ .HandlerName }}Error
 	if {{ if .ResultType }}result, {{ end }}handlerError = m.handler.{{ .HandlerName }}(r.Context(),
 		{{- range .Params -}}
@@ -195,11 +445,22 @@ func (m *middleware) {{ .Name }}(w http.ResponseWriter, r *http.Request, {{ if .
 	}
 
 	{{ if .ResultType -}}
-		if errs = result.Validate(); len(errs) > 0 {
+		{{ if .IsResultSlice -}}
+			// a slice has no Validate method, so the items are validated one by one
+			for i := range result {
+				for _, e := range result[i].Validate() {
+					e.Path = fmt.Sprintf("/%d%s", i, e.Path)
+					errs = append(errs, e)
+				}
+			}
+		{{ else -}}
+			errs = result.Validate()
+		{{ end -}}
+		if len(errs) > 0 {
 			err := errors.New("Invalid response data")
 			log.WithFields(log.Fields{
 				"dataType": "{{ if .IsResultSlice }}[]{{ end }}{{ if .ReadOnlyResult }}ReadOnly{{ end }}{{ .ResultType }}",
-				"error": strings.Join(errs, "\n"),
+				"error": strings.Join(errs.Strings(), "\n"),
 			}).Error(err)
 			{{ template "unexpectedError" .CatchAllError -}}
 			return
@@ -211,6 +472,22 @@ func (m *middleware) {{ .Name }}(w http.ResponseWriter, r *http.Request, {{ if .
 			log.WithField("error", err).Error("Failed to write OK response")
 		}
 	{{- end }}
+	{{- end }}
+}
+
+{{ if .EventType -}}
+	{{ template "eventSender" . }}
+{{ end -}}
+{{ end -}}
+
+{{ range .SharedParams -}}
+// parse{{ .Shared }}Param parses {{ if .HasValidation }}and validates {{ end }}the {{ .RawName }} {{ .Location }} parameter, which is defined in the parameters of the spec
+func parse{{ .Shared }}Param({{ template "paramSource" .Location }} {{ if eq .Location "path" }}httprouter.Params{{ else if eq .Location "header" }}*http.Request{{ else }}url.Values{{ end }}) ({{ if .IsArray }}[]{{ end }}{{ .Type }}{{ if .HasValidation }}, model.ValidationErrors{{ end }}) {
+	{{ if .HasValidation -}}
+		var errs model.ValidationErrors
+	{{ end -}}
+	{{ template "parseParam" . }}
+	return {{ .Name }}{{ if .HasValidation }}, errs{{ end }}
 }
 
 {{ end -}}
@@ -241,6 +518,182 @@ func respondJSON(w http.ResponseWriter, data interface{}, dataType string, statu
 	}
 }
 
+{{ if .HasEventStream -}}
+	// heartbeat comments keep proxies from closing idle event streams
+	const eventStreamHeartbeat = 15 * time.Second
+
+	type eventStream struct {
+		ctx     context.Context
+		w       http.ResponseWriter
+		flusher http.Flusher
+		lock    sync.Mutex
+		done    chan struct{}
+	}
+
+	func newEventStream(ctx context.Context, w http.ResponseWriter) (*eventStream, error) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			return nil, errors.New("Response writer does not support flushing")
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		s := &eventStream{
+			ctx: ctx,
+			w: w,
+			flusher: flusher,
+			done: make(chan struct{}),
+		}
+
+		go s.heartbeat()
+
+		return s, nil
+	}
+
+	func (s *eventStream) heartbeat() {
+		ticker := time.NewTicker(eventStreamHeartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.write(": heartbeat\n\n"); err != nil {
+					return
+				}
+			case <-s.ctx.Done():
+				return
+			case <-s.done:
+				return
+			}
+		}
+	}
+
+	// close stops the heartbeat; nothing is written to the stream after close returns
+	func (s *eventStream) close() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		close(s.done)
+	}
+
+	func (s *eventStream) write(message string) error {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		select {
+		case <-s.done:
+			return errors.New("Event stream is closed")
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		default:
+		}
+
+		if _, err := io.WriteString(s.w, message); err != nil {
+			return err
+		}
+		s.flusher.Flush()
+
+		return nil
+	}
+
+	func (s *eventStream) send(event string, data interface{}) error {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		message := "data: " + string(payload) + "\n\n"
+		if event != "" {
+			message = "event: " + event + "\n" + message
+		}
+
+		return s.write(message)
+	}
+{{ end -}}
+
+{{ if .HasBody -}}
+	// decodeBody decodes the JSON body of a request
+	// maxSize limits the size of the body in bytes, unless it is 0
+	// strict rejects data after the JSON value
+	func decodeBody(w http.ResponseWriter, r *http.Request, data interface{}, maxSize int64, disallowUnknownFields, strict bool) error {
+		body := io.Reader(r.Body)
+		{{ if .HasBodySizeLimit -}}
+			if maxSize > 0 {
+				if r.ContentLength > maxSize {
+					return errBodyTooLarge
+				}
+				body = http.MaxBytesReader(w, r.Body, maxSize)
+			}
+		{{ end }}
+
+		decoder := json.NewDecoder(body)
+		if disallowUnknownFields {
+			decoder.DisallowUnknownFields()
+		}
+
+		if err := decoder.Decode(data); err != nil {
+			return {{ if .HasBodySizeLimit }}bodyTooLarge(err){{ else }}err{{ end }}
+		}
+
+		if strict {
+			if _, err := decoder.Token(); err != io.EOF {
+				{{ if .HasBodySizeLimit -}}
+					if err = bodyTooLarge(err); err == errBodyTooLarge {
+						return err
+					}
+				{{ end -}}
+				return errors.New("Unexpected data after the JSON body")
+			}
+		}
+
+		return nil
+	}
+{{ end -}}
+
+{{ if .HasBody -}}
+	// bodyValidationError turns an error from decoding the body into a validation error
+	func bodyValidationError(err error) model.ValidationError {
+		e := model.ValidationError{
+			Location: "body",
+			Rule:     "json",
+			Message:  err.Error(),
+		}
+
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+			if typeErr.Field != "" {
+				e.Path = "/" + strings.Replace(typeErr.Field, ".", "/", -1)
+			}
+			e.Rule = "type"
+			e.Limit = typeErr.Type.String()
+			e.Value = typeErr.Value
+		} else if field := strings.TrimPrefix(err.Error(), "json: unknown field "); field != err.Error() {
+			// encoding/json has no error type for unknown fields
+			e.Rule = "additionalProperties"
+			e.Limit = false
+			e.Value, _ = strconv.Unquote(field)
+		}
+
+		return e
+	}
+{{ end -}}
+
+{{ if .HasBodySizeLimit -}}
+	var errBodyTooLarge = errors.New("Request body too large")
+
+	// bodyTooLarge returns errBodyTooLarge for the error of http.MaxBytesReader, and other errors as they are
+	func bodyTooLarge(err error) error {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return errBodyTooLarge
+		}
+		return err
+	}
+{{ end -}}
+
 {{ if .HasParameterArray -}}
 	func parseArray(s string) []string {
 		// we treat the empty string as an empty array, rather than an array with one empty element
@@ -251,18 +704,55 @@ func respondJSON(w http.ResponseWriter, data interface{}, dataType string, statu
 	}
 {{ end -}}
 
-{{ if .HasParameterStringValidation -}}
-	func validateString(s, name string, minLength, maxLength *int, enum []string) (errs []string) {
-		if minLength != nil {
-			if len(s) < *minLength {
-				errs = append(errs, fmt.Sprintf("%s should be no shorter than %d characters", name, *minLength))
+{{ if .HasParameterTime -}}
+	// parseTime parses a time parameter; the layouts unix and unix-milli are timestamps in seconds and milliseconds
+	func parseTime(value, layout string) (t time.Time, err error) {
+		switch layout {
+		case "unix", "unix-milli":
+			var timestamp int64
+			if timestamp, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return
+			}
+			if layout == "unix" {
+				t = time.Unix(timestamp, 0)
+			} else {
+				t = time.Unix(0, timestamp*int64(time.Millisecond))
 			}
+			return
 		}
 
-		if maxLength != nil {
-			if len(s) > *maxLength {
-				errs = append(errs, fmt.Sprintf("%s should be no longer than %d characters", name, *maxLength))
-			}
+		return time.Parse(layout, value)
+	}
+{{ end -}}
+
+{{ if .HasParameterValidation -}}
+	// newParamError returns a validation error for a parameter at location
+	func newParamError(location, path, name, rule string, limit, value interface{}) model.ValidationError {
+		e := model.NewValidationError(path, name, rule, limit, value)
+		e.Location = location
+		return e
+	}
+{{ end -}}
+
+{{ if .HasParameterNumber -}}
+	// isMultipleOf checks whether value is a multiple of factor, allowing for floating point rounding errors
+	func isMultipleOf(value, factor float64) bool {
+		quotient := value / factor
+		return math.Abs(quotient-math.Round(quotient)) < 1e-9
+	}
+{{ end -}}
+
+{{ if .HasParameterStringValidation -}}
+	// validateString validates a string parameter; a negative minLength or maxLength is not checked
+	// pattern is not checked when nil
+	// format is the name of a format in the strfmt registry; an empty string (a missing parameter) is not checked against it
+	func validateString(s, location, path, name string, minLength, maxLength int64, enum []string, pattern *regexp.Regexp, format string) (errs model.ValidationErrors) {
+		if minLength >= 0 && int64(len(s)) < minLength {
+			errs = append(errs, model.NewValidationError(path, name, "minLength", minLength, s))
+		}
+
+		if maxLength >= 0 && int64(len(s)) > maxLength {
+			errs = append(errs, model.NewValidationError(path, name, "maxLength", maxLength, s))
 		}
 
 		if enum != nil {
@@ -274,39 +764,55 @@ func respondJSON(w http.ResponseWriter, data interface{}, dataType string, statu
 				}
 			}
 			if !found {
-				errs = append(errs, fmt.Sprintf("%s is not an allowed value for %s", s, name))
+				errs = append(errs, model.NewValidationError(path, name, "enum", enum, s))
 			}
 		}
 
-		return
+		if pattern != nil && !pattern.MatchString(s) {
+			errs = append(errs, model.NewValidationError(path, name, "pattern", pattern.String(), s))
+		}
+
+		if format != "" && s != "" && !strfmt.Default.Validates(format, s) {
+			errs = append(errs, model.NewValidationError(path, name, "format", format, s))
+		}
+
+		return errs.WithLocation(location)
 	}
 {{ end -}}
 
 {{ if .HasParameterArrayValidation -}}
-	func validateArray(a []string, name string, minItems, maxItems *int, uniqueItems bool) (errs []string) {
-		if minItems != nil {
-			if len(a) < *minItems {
-				errs = append(errs, fmt.Sprintf("%s should have no less than %d elements", name, *minItems))
-			}
+	// validateArray validates an array parameter; a negative minItems or maxItems is not checked
+	func validateArray(a []string, location, path, name string, minItems, maxItems int64, uniqueItems bool) (errs model.ValidationErrors) {
+		if minItems >= 0 && int64(len(a)) < minItems {
+			errs = append(errs, model.NewValidationError(path, name, "minItems", minItems, len(a)))
 		}
 
-		if maxItems != nil {
-			if len(a) > *maxItems {
-				errs = append(errs, fmt.Sprintf("%s should have no more than %d elements", name, *maxItems))
-			}
+		if maxItems >= 0 && int64(len(a)) > maxItems {
+			errs = append(errs, model.NewValidationError(path, name, "maxItems", maxItems, len(a)))
 		}
 
 		if uniqueItems {
 			seen := map[string]struct{}{}
 			for _, elt := range a {
 				if _, duplicate := seen[elt]; duplicate {
-					errs = append(errs, fmt.Sprintf("%s occurs multiple times in %s", elt, name))
+					errs = append(errs, model.NewValidationError(path, name, "uniqueItems", true, elt))
 				}
 				seen[elt] = struct{}{}
 			}
 		}
 
-		return
+		return errs.WithLocation(location)
 	}
 {{ end -}}
+
+{{ range .Enums }}
+	// {{ .Name }} is the type of an enum parameter
+	type {{ .Name }} {{ .Type }}
+
+	{{ template "enum" dict "Name" .Name "Enum" .Enum "Package" "model." }}
+{{ end -}}
+
+{{ range .Patterns -}}
+	var regexp{{ .Name }} = regexp.MustCompile(`+"`"+`{{ .Pattern }}`+"`"+`)
+{{ end -}}
 `)
@@ -0,0 +1,193 @@
+package templates
+
+// ScaffoldHandler is a template for the handler of the scaffold
+var ScaffoldHandler = parse("scaffoldHandler", `
+package main
+
+// This file was generated once as a starting point, and is not overwritten
+// Replace the errors by the implementation of the operations
+
+import (
+	"context"
+
+	router "{{ .RouterImport }}"
+	model "{{ .ModelImport }}"
+	{{ range .Imports -}}
+		"{{ . }}"
+	{{ end -}}
+)
+
+// handler implements the operations of the service
+type handler struct{}
+
+{{ range .Routes -}}
+	// {{ .HandlerName }} handles {{ .Method }} {{ .Route }}
+	func (h *handler) {{ .HandlerName }}(ctx context.Context,
+		{{- range .Params -}}
+			{{ .Name }} {{ if .IsArray }}[]{{ end }}{{ if .Enum }}router.{{ end }}{{ .Type }},
+		{{- end -}}
+		{{- if .Body -}}
+			{{ .Body.Name }} model.{{ .Body.Type }},
+		{{- end -}}
+		{{- if .EventType -}}
+			sender router.{{ .HandlerName }}Sender
+		{{- end -}}
+	) (
+		{{- if .ResultType -}}
+			result {{ if .IsResultSlice }}[]{{ end }}model.{{ if .ReadOnlyResult }}ReadOnly{{ end }}{{ .ResultType }},
+		{{- end -}}
+		err model.{{ .HandlerName }}Error) {
+		{{ if .NotImplemented -}}
+			err = {{ .NotImplemented }}
+			return
+		{{- else -}}
+			// the operation has no error type for 500, so a panic is the way to get a 500 response from the router
+			panic("Not implemented")
+		{{- end }}
+	}
+
+{{ end -}}
+`)
+
+// ScaffoldErrorTransformer is a template for the ErrorTransformer of the scaffold
+var ScaffoldErrorTransformer = parse("scaffoldErrorTransformer", `
+package main
+
+// This file was generated once as a starting point, and is not overwritten
+
+import (
+	"strings"
+
+	model "{{ .ModelImport }}"
+)
+
+// errorTransformer turns the errors of the router into the error types of the spec
+type errorTransformer struct{}
+
+{{ range .BadRequestErrors -}}
+	{{ if eq .Type "string" -}}
+		// ValidationErrorsToString joins the messages of the validation errors
+		func (errorTransformer) ValidationErrorsToString(errs model.ValidationErrors) string {
+	{{- else -}}
+		// ValidationErrorsTo{{ .Type }} turns the validation errors into the {{ .Type }} type of the spec
+		func (errorTransformer) ValidationErrorsTo{{ .Type }}(errs model.ValidationErrors) model.{{ .Type }} {
+	{{- end }}
+		return {{ .Convert }}
+	}
+
+{{ end -}}
+
+{{ range .InternalServerErrors -}}
+	{{ if eq .Type "string" -}}
+		// ErrorToString returns the message of the error
+		func (errorTransformer) ErrorToString(err error) string {
+	{{- else -}}
+		// ErrorTo{{ .Type }} turns the error into the {{ .Type }} type of the spec
+		func (errorTransformer) ErrorTo{{ .Type }}(err error) model.{{ .Type }} {
+	{{- end }}
+		return {{ .Convert }}
+	}
+
+{{ end -}}
+`)
+
+// ScaffoldMain is a template for the main function of the scaffold
+var ScaffoldMain = parse("scaffoldMain", `
+package main
+
+// This file was generated once as a starting point, and is not overwritten
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	{{ if .HasEventStream -}}
+		"strings"
+	{{ end -}}
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	router "{{ .RouterImport }}"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 30*time.Second, "time that running requests get to finish when the server stops")
+	flag.Parse()
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           router.NewServer(&handler{}, errorTransformer{}, reportPanic),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	{{ if .HasEventStream -}}
+		server.Handler = cancelStreamsOnShutdown(server, server.Handler)
+
+	{{ end -}}
+
+	// stop on Ctrl-C, and on SIGTERM from e.g. Docker or Kubernetes
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.WithField("addr", *addr).Info("Listening")
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		log.WithField("error", err).Fatal("Failed to serve")
+	case <-stop:
+	}
+
+	log.Info("Shutting down")
+
+	// stop accepting connections, and wait for the running requests
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.WithField("error", err).Error("Failed to shut down gracefully")
+	}
+}
+
+// reportPanic is called when a handler panics; the client gets a 500 response
+func reportPanic(p interface{}) {
+	log.WithField("panic", p).Error("Handler panicked")
+}
+
+{{ if .HasEventStream -}}
+	// cancelStreamsOnShutdown cancels the context of event streams when the server stops, as they only end when the
+	// client disconnects or the request context is cancelled; other requests get the shutdown timeout to finish
+	// Event streams are recognized by their Accept header, which browsers always send for them
+	func cancelStreamsOnShutdown(server *http.Server, next http.Handler) http.Handler {
+		shutdown, stopStreams := context.WithCancel(context.Background())
+		server.RegisterOnShutdown(stopStreams)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+				ctx, cancel := context.WithCancel(r.Context())
+				defer cancel()
+
+				go func() {
+					select {
+					case <-shutdown.Done():
+						cancel()
+					case <-ctx.Done():
+					}
+				}()
+
+				r = r.WithContext(ctx)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+{{ end -}}
+`)
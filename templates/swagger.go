@@ -1,9 +1,9 @@
 package templates
 
 var Swagger = parse("swagger", `
-package generated
+package {{ .Package }}
 
 const Swagger = '
-{{ . -}}
+{{ .Swagger -}}
 '
 `)
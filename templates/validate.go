@@ -2,82 +2,141 @@ package templates
 
 // Validate is a template for the validate file
 var Validate = parse("validate", `
+	{{/* Input: true if the type is in the shared model package, which exports validateAt */}}
+	{{ define "validateAt" }}{{ if . }}ValidateAt{{ else }}validateAt{{ end }}{{ end }}
+
+	{{/* Input: type name */ -}}
+	{{ define "exportedValidateAt" }}
+		// ValidateAt validates a {{ . }} that is located at path, and is called name in messages
+		// The models that use the shared types call it to validate nested values
+		func (s *{{ . }}) ValidateAt(path, name string) ValidationErrors {
+			return s.validateAt(path, name)
+		}
+	{{ end -}}
+
 	{{/* Input: { Type, ReadOnly } */}}
 	{{ define "validateType" -}}
 		// Validate validates a {{ .ReadOnly }}{{ .Type.Name }} based on the swagger spec
-		func (s *{{ .ReadOnly }}{{ .Type.Name }}) Validate() (errors []string) {
-			{{ if .Type.IsStruct -}}
-				{{ if .ReadOnly -}}
-					if e := s.{{ .Type.Name }}.Validate(); len(e) > 0 {
-						errors = append(errors, e...)
-					}
-				{{ end -}}
+		func (s *{{ .ReadOnly }}{{ .Type.Name }}) Validate() ValidationErrors {
+			return s.validateAt("", "{{ if not .Type.IsStruct }}{{ .Type.Name }}{{ end }}")
+		}
 
+		// validateAt validates a {{ .ReadOnly }}{{ .Type.Name }} that is located at path, and is called name in messages
+		func (s *{{ .ReadOnly }}{{ .Type.Name }}) validateAt(path, name string) (errors ValidationErrors) {
+			{{ if .Type.IsStruct -}}
+				{{/* the read-only variant embeds the other one, so it validates all properties itself */ -}}
 				{{ range .Type.Props -}}
-					{{ if eq (eq $.ReadOnly "ReadOnly") .IsReadOnly -}}
-						{{ if .IsRequired }}
-							if s.{{ .Name }} == nil {
-								errors = append(errors, "{{ .JSONName }} is required")
+					{{ if and $.ReadOnly .IsWriteOnly }}
+						// {{ .JSONName }} is write-only, so it must not be in a response
+						if {{ if .Nullable }}s.{{ $.Type.Name }}.{{ .Name }}.Present{{ else }}s.{{ $.Type.Name }}.{{ .Name }} != nil{{ end }} {
+							errors = append(errors, NewValidationError(path + {{ printf "%q" (print "/" (pointer .JSONName)) }}, propertyName(name, {{ printf "%q" .JSONName }}), "writeOnly", nil, nil))
+						}
+					{{ else if or $.ReadOnly (not .IsReadOnly) -}}
+						{{ $path := printf "path + %q" (print "/" (pointer .JSONName)) -}}
+						{{ $name := printf "propertyName(name, %q)" .JSONName -}}
+						{{ $validation := templateAsString "validateProperty" (dict "Prop" . "Path" $path "Name" $name "RegexpName" (print $.Type.Name .Name)) -}}
+						{{ if .NonPointer -}}
+							{{ $validation }}
+						{{ else if .IsRequired }}
+							if {{ if .Nullable }}!s.{{ .Name }}.Present{{ else }}s.{{ .Name }} == nil{{ end }} {
+								errors = append(errors, NewValidationError({{ $path }}, {{ $name }}, "required", nil, nil))
+							}
+							{{- if $validation }} else {{ if .Nullable }}if !s.{{ .Name }}.Null {{ end }}{
+								{{ $validation }}
 							}
+							{{- end }}
+						{{ else if $validation }}
+							// {{ .JSONName }} is optional; only validate it when present
+							if {{ template "propPresent" . }}{{ if .Nullable }} && !s.{{ .Name }}.Null{{ end }} {
+								{{ $validation }}
+							}
+						{{ end -}}
+					{{ end -}}
+				{{ end }}
 
-							{{- if .IsSlice -}}
-								{{ $else := templateAsString "validateSlice" (dict "Validation" .Validation.Array "Slice" (print "s." .Name) "Name" .JSONName "ItemType" .ItemType "ItemValidation" .ItemValidation "RegexpName" (print $.Type.Name .Name)) -}}
-								{{- if $else -}}
-									else {
-										{{ $else }}
-									}
-								{{ end -}}
-							{{- else if eq .Type "int64" -}}
-								{{ $else := templateAsString "validateInt64" (dict "Validation" .Validation.Int "Int" (print "*s." .Name) "Name" .JSONName) -}}
-								{{- if $else -}}
-									else {
-										{{ $else }}
+				{{ with .Type.Validation.Object -}}
+					{{ if or .HasMinProperties .HasMaxProperties }}
+						properties := 0
+						{{ range $.Type.Props -}}
+							{{ if or (and $.ReadOnly (not .IsWriteOnly)) (and (not $.ReadOnly) (not .IsReadOnly)) -}}
+								{{ if .NonPointer -}}
+									properties++
+								{{ else -}}
+									if {{ template "propPresent" . }} {
+										properties++
 									}
 								{{ end -}}
-							{{- else if eq .Type "float64" -}}
-								{{ $else := templateAsString "validateFloat64" (dict "Validation" .Validation.Number "Number" (print "*s." .Name) "Name" .JSONName) -}}
-								{{- if $else -}}
-									else {
-										{{ $else }}
-									}
-								{{ end -}}
-							{{- else if eq .Type "string" -}}
-								{{ $else := templateAsString "validateString" (dict "Validation" .Validation.String "String" (print "*s." .Name) "Name" .JSONName "RegexpName" (print $.Type.Name .Name)) -}}
-								{{- if $else -}}
-									else {
-										{{ $else }}
-									}
-								{{ end -}}
-							{{- else if not (eq .Type "bool" "time.Time") -}}
-								else {
-									if e := s.{{ .Name }}.Validate(); len(e) > 0 {
-										errors = append(errors, e...)
-									}
-								}
 							{{ end -}}
 						{{ end -}}
+
+						{{ if .HasMinProperties }}
+							if properties < {{ .MinProperties }} {
+								errors = append(errors, NewValidationError(path, name, "minProperties", {{ .MinProperties }}, properties))
+							}
+						{{ end -}}
+
+						{{ if .HasMaxProperties }}
+							if properties > {{ .MaxProperties }} {
+								errors = append(errors, NewValidationError(path, name, "maxProperties", {{ .MaxProperties }}, properties))
+							}
+						{{ end }}
 					{{ end -}}
-				{{ end }}
+				{{ end -}}
 			{{ else }}{{/* .Type.IsSlice */ -}}
-				{{ template "validateSlice" dict "Validation" .Type.Validation.Array "Slice" "*s" "Name" .Type.Name "ItemType" (print .ReadOnly .Type.ItemType) "ItemValidation" .Type.ItemValidation "RegexpName" $.Type.Name -}}
+				{{ template "validateSlice" dict "Validation" .Type.Validation.Array "Slice" "*s" "Path" "path" "Name" "name" "ItemType" (print .ReadOnly .Type.ItemType) "ItemValidation" .Type.ItemValidation "SharedItems" .Type.HasSharedType "RegexpName" $.Type.Name -}}
 			{{ end -}}
 
 			return
 		}
 	{{ end -}}
 
-	{{/* Input: { Slice, Name, Validation, ItemType, ItemValidation, RegexpName } */ -}}
+	{{/* Input: prop; a Go expression that is true when the property is present */ -}}
+	{{ define "propPresent" -}}
+		{{ if .Nullable }}s.{{ .Name }}.Present{{ else }}s.{{ .Name }} != nil{{ end }}
+	{{- end }}
+
+	{{/* Input: { Prop, Path, Name, RegexpName } */ -}}
+	{{/* Validates a property that is present; renders nothing if there is nothing to validate */ -}}
+	{{ define "validateProperty" -}}
+		{{ with .Prop -}}
+			{{ $field := print "s." .Name -}}
+			{{ if .Nullable }}{{ $field = print "s." .Name ".Value" }}{{ end -}}
+			{{ $value := $field -}}
+			{{ if not (or .IsSlice .Nullable .NonPointer (eq .Type "[]byte")) }}{{ $value = print "*" $field }}{{ end -}}
+
+			{{ if .Validation.Custom -}}
+				{{ template "validateCustom" dict "Validation" .Validation.Custom "Value" $value "Path" $.Path "Name" $.Name -}}
+			{{ else if .IsSlice -}}
+				{{ template "validateSlice" dict "Validation" .Validation.Array "Slice" $value "Path" $.Path "Name" $.Name "ItemType" .ItemType "ItemValidation" .ItemValidation "SharedItems" .HasSharedType "RegexpName" $.RegexpName -}}
+			{{ else if eq .Type "int32" "int64" "uint32" "uint64" -}}
+				{{ template "validateInteger" dict "Validation" .Validation.Int "Int" $value "Path" $.Path "Name" $.Name -}}
+			{{ else if eq .Type "float32" "float64" -}}
+				{{ template "validateNumber" dict "Validation" .Validation.Number "Number" $value "Path" $.Path "Name" $.Name -}}
+			{{ else if eq .Type "string" -}}
+				{{ template "validateString" dict "Validation" .Validation.String "String" $value "Path" $.Path "Name" $.Name "RegexpName" $.RegexpName -}}
+			{{ else if not (eq .Type "bool" "time.Time" "strfmt.Date" "[]byte") -}}
+				if e := {{ $field }}.{{ template "validateAt" .HasSharedType }}({{ $.Path }}, {{ $.Name }}); len(e) > 0 {
+					errors = append(errors, e...)
+				}
+			{{- end -}}
+		{{ end -}}
+	{{ end -}}
+
+	{{/* Input: { Slice, Path, Name, Validation, ItemType, ItemValidation, SharedItems, RegexpName } */ -}}
+	{{/* Path and Name are Go expressions */ -}}
 	{{ define "validateSlice" -}}
+		{{ $itemPath := printf "fmt.Sprintf(\"%%s/%%d\", %s, i)" .Path -}}
+		{{ $itemName := printf "fmt.Sprintf(\"%%s[%%d]\", %s, i)" .Name -}}
 		{{ if .Validation -}}
 			{{ if .Validation.HasMaxItems }}
 				if len({{ .Slice }}) > {{ .Validation.MaxItems }} {
-					errors = append(errors, "{{ .Name }} should have no more than {{ .Validation.MaxItems }} elements")
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "maxItems", {{ .Validation.MaxItems }}, len({{ .Slice }})))
 				}
 			{{ end -}}
 
 			{{ if .Validation.HasMinItems }}
 				if len({{ .Slice }}) < {{ .Validation.MinItems }} {
-					errors = append(errors, "{{ .Name }} should have no less than {{ .Validation.MinItems }} elements")
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "minItems", {{ .Validation.MinItems }}, len({{ .Slice }})))
 				}
 			{{ end -}}
 
@@ -87,148 +146,294 @@ var Validate = parse("validate", `
 					unique[elt] = struct{}{}
 				}
 				if len(unique) < len({{ .Slice }}) {
-					errors = append(errors, "{{ .Name }} contains duplicate elements")
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "uniqueItems", true, nil))
 				}
 			{{ end -}}
 		{{ end -}}
 
-		{{ if eq .ItemType "int64" -}}
+		{{ if .ItemValidation.Custom }}
+			for i, elt := range {{ .Slice }} {
+				{{- template "validateCustom" dict "Validation" .ItemValidation.Custom "Value" "elt" "Path" $itemPath "Name" $itemName -}}
+			}
+		{{ else if eq .ItemType "int32" "int64" "uint32" "uint64" -}}
 			{{ if .ItemValidation.Int }}
 				for i, elt := range {{ .Slice }} {
-					{{- template "validateInt64" dict "Validation" .ItemValidation.Int "Int" "elt" "Name" (print .Name "[%d]") "FormatParams" "i" -}}
+					{{- template "validateInteger" dict "Validation" .ItemValidation.Int "Int" "elt" "Path" $itemPath "Name" $itemName -}}
 				}
 			{{ end -}}
-		{{ else if eq .ItemType "float64" -}}
+		{{ else if eq .ItemType "float32" "float64" -}}
 			{{ if .ItemValidation.Number }}
 				for i, elt := range {{ .Slice }} {
-					{{- template "validateFloat64" dict "Validation" .ItemValidation.Number "Number" "elt" "Name" (print .Name "[%d]") "FormatParams" "i" -}}
+					{{- template "validateNumber" dict "Validation" .ItemValidation.Number "Number" "elt" "Path" $itemPath "Name" $itemName -}}
 				}
 			{{ end -}}
 		{{ else if eq .ItemType "string" -}}
 			{{ if .ItemValidation.String }}
 				for i, elt := range {{ .Slice }} {
-					{{- template "validateString" dict "Validation" .ItemValidation.String "String" "elt" "Name" (print .Name "[%d]") "RegexpName" $.RegexpName "FormatParams" "i" -}}
+					{{- template "validateString" dict "Validation" .ItemValidation.String "String" "elt" "Path" $itemPath "Name" $itemName "RegexpName" $.RegexpName -}}
 				}
 			{{ end -}}
-		{{ else if not (eq .ItemType "bool" "time.Time") }}
-			for _, elt := range {{ .Slice }} {
-				if e := elt.Validate(); len(e) > 0 {
+		{{ else if not (eq .ItemType "bool" "time.Time" "strfmt.Date" "[]byte") }}
+			for i, elt := range {{ .Slice }} {
+				if e := elt.{{ template "validateAt" .SharedItems }}({{ $itemPath }}, {{ $itemName }}); len(e) > 0 {
 					errors = append(errors, e...)
 				}
 			}
 		{{ end -}}
 	{{ end -}}
 
-	{{/* Input: { Int, Name, Validation, FormatParams (optional) } */ -}}
-	{{ define "validateInt64" -}}
+	{{/* Input: { Value, Path, Name, Validation } */ -}}
+	{{ define "validateCustom" }}
+		if err := {{ .Validation.Validate }}({{ .Value }}); err != nil {
+			errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "format", {{ printf "%q" .Validation.Format }}, {{ .Value }}))
+		}
+	{{ end -}}
+
+	{{/* Input: { Int, Path, Name, Validation } */ -}}
+	{{ define "validateInteger" -}}
 		{{ if .Validation -}}
 			{{ if .Validation.Enum }}
 				switch {{ .Int }} {
 				case {{ .Validation.FlattenedEnum }}: // ok
 				default:
-					errors = append(errors, fmt.Sprintf("%d is not an allowed value for {{ .Name }}", {{ .Int }}
-						{{- if .FormatParams }}, {{ .FormatParams }}{{ end }}))
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "enum", []int64{ {{ .Validation.FlattenedEnum }} }, {{ .Int }}))
 				}
 			{{ end -}}
 
 			{{- if .Validation.HasMaximum }}
 				if {{ .Int }} {{ if .Validation.ExclusiveMaximum }}>={{ else }}>{{ end }} {{ .Validation.Maximum }} {
-					errors = append(errors,
-						{{- if .FormatParams }}fmt.Sprintf({{ end -}}
-						"{{ .Name }} should be {{ if .Validation.ExclusiveMaximum }}less than{{ else }}at most{{ end }} {{ .Validation.Maximum }}"
-						{{- if .FormatParams }}, {{ .FormatParams }}){{ end }})
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "{{ if .Validation.ExclusiveMaximum }}exclusiveMaximum{{ else }}maximum{{ end }}", {{ .Validation.Maximum }}, {{ .Int }}))
 				}
 			{{ end -}}
 
 			{{- if .Validation.HasMinimum }}
 				if {{ .Int }} {{ if .Validation.ExclusiveMinimum }}<={{ else }}<{{ end }} {{ .Validation.Minimum }} {
-					errors = append(errors,
-						{{- if .FormatParams }}fmt.Sprintf({{ end -}}
-						"{{ .Name }} should be {{ if .Validation.ExclusiveMinimum }}more than{{ else }}at least{{ end }} {{ .Validation.Minimum }}"
-						{{- if .FormatParams }}, {{ .FormatParams }}){{ end }})
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "{{ if .Validation.ExclusiveMinimum }}exclusiveMinimum{{ else }}minimum{{ end }}", {{ .Validation.Minimum }}, {{ .Int }}))
+				}
+			{{ end -}}
+
+			{{- if .Validation.HasMultipleOf }}
+				if {{ .Int }} % {{ .Validation.MultipleOf }} != 0 {
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "multipleOf", {{ .Validation.MultipleOf }}, {{ .Int }}))
 				}
 			{{ end -}}
 		{{ end -}}
 	{{ end -}}
 
-	{{/* Input: { Number, Name, Validation, FormatParams (optional) } */ -}}
-	{{ define "validateFloat64" -}}
+	{{/* Input: { Number, Path, Name, Validation } */ -}}
+	{{ define "validateNumber" -}}
 		{{ if .Validation -}}
 			{{ if .Validation.Enum }}
 				switch {{ .Number }} {
 				case {{ .Validation.FlattenedEnum }}: // ok
 				default:
-					errors = append(errors, fmt.Sprintf("%f is not an allowed value for {{ .Name }}", {{ .Number }}
-						{{- if .FormatParams }}, {{ .FormatParams }}{{ end }}))
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "enum", []float64{ {{ .Validation.FlattenedEnum }} }, {{ .Number }}))
 				}
 			{{ end -}}
 
 			{{- if .Validation.HasMaximum }}
 				if {{ .Number }} {{ if .Validation.ExclusiveMaximum }}>={{ else }}>{{ end }} {{ .Validation.Maximum }} {
-					errors = append(errors,
-						{{- if .FormatParams }}fmt.Sprintf({{ end -}}
-						"{{ .Name }} should be {{ if .Validation.ExclusiveMaximum }}less than{{ else }}at most{{ end }} {{ .Validation.Maximum }}"
-						{{- if .FormatParams }}, {{ .FormatParams }}){{ end }})
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "{{ if .Validation.ExclusiveMaximum }}exclusiveMaximum{{ else }}maximum{{ end }}", {{ .Validation.Maximum }}, {{ .Number }}))
 				}
 			{{ end -}}
 
 			{{- if .Validation.HasMinimum }}
 				if {{ .Number }} {{ if .Validation.ExclusiveMinimum }}<={{ else }}<{{ end }} {{ .Validation.Minimum }} {
-					errors = append(errors,
-						{{- if .FormatParams }}fmt.Sprintf({{ end -}}
-						"{{ .Name }} should be {{ if .Validation.ExclusiveMinimum }}more than{{ else }}at least{{ end }} {{ .Validation.Minimum }}"
-						{{- if .FormatParams }}, {{ .FormatParams }}){{ end }})
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "{{ if .Validation.ExclusiveMinimum }}exclusiveMinimum{{ else }}minimum{{ end }}", {{ .Validation.Minimum }}, {{ .Number }}))
+				}
+			{{ end -}}
+
+			{{- if .Validation.HasMultipleOf }}
+				if !isMultipleOf(float64({{ .Number }}), {{ .Validation.MultipleOf }}) {
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "multipleOf", {{ .Validation.MultipleOf }}, {{ .Number }}))
 				}
 			{{ end -}}
 		{{ end -}}
 	{{ end -}}
 
-	{{/* Input: { String, Name, Validation, RegexpName, FormatParams (optional) } */ -}}
+	{{/* Input: { String, Path, Name, Validation, RegexpName } */ -}}
 	{{ define "validateString" -}}
 		{{ if .Validation -}}
 			{{ if .Validation.Enum }}
 				switch {{ .String }} {
 				case {{ .Validation.FlattenedEnum }}: // ok
 				default:
-					errors = append(errors, fmt.Sprintf("%s is not an allowed value for {{ .Name }}", {{ .String }}
-						{{- if .FormatParams }}, {{ .FormatParams }}{{ end }}))
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "enum", []string{ {{ .Validation.FlattenedEnum }} }, {{ .String }}))
 				}
 			{{ end -}}
 
 			{{- if .Validation.HasMaxLength }}
 				if len({{ .String }}) > {{ .Validation.MaxLength }} {
-					errors = append(errors,
-						{{- if .FormatParams }}fmt.Sprintf({{ end -}}
-						"{{ .Name }} should be no longer than {{ .Validation.MaxLength }} characters"
-						{{- if .FormatParams }}, {{ .FormatParams }}){{ end }})
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "maxLength", {{ .Validation.MaxLength }}, {{ .String }}))
 				}
 			{{ end -}}
 
 			{{- if .Validation.HasMinLength }}
 				if len({{ .String }}) < {{ .Validation.MinLength }} {
-					errors = append(errors,
-						{{- if .FormatParams }}fmt.Sprintf({{ end -}}
-						"{{ .Name }} should be no shorter than {{ .Validation.MinLength }} characters"
-						{{- if .FormatParams }}, {{ .FormatParams }}){{ end }})
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "minLength", {{ .Validation.MinLength }}, {{ .String }}))
 				}
 			{{ end -}}
 
 			{{- if .Validation.HasPattern }}
 				if !regexp{{ $.RegexpName }}.MatchString({{ .String }}) {
-					errors = append(errors,
-						{{- if .FormatParams }}fmt.Sprintf({{ end -}}
-						"{{ .Name }} should match the regex {{ .Validation.Pattern }}"
-						{{- if .FormatParams }}, {{ .FormatParams }}){{ end }})
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "pattern", {{ printf "%q" .Validation.Pattern }}, {{ .String }}))
+				}
+			{{ end -}}
+
+			{{- if .Validation.Format }}
+				if !strfmt.Default.Validates({{ printf "%q" .Validation.Format }}, {{ .String }}) {
+					errors = append(errors, NewValidationError({{ .Path }}, {{ .Name }}, "format", {{ printf "%q" .Validation.Format }}, {{ .String }}))
 				}
 			{{ end -}}
 		{{ end -}}
 	{{ end -}}
 
-	package model
+	package {{ .Package }}
 
 	// This is a generated file
 	// Manual changes will be overwritten
 
+	{{ if .Imports -}}
+		import (
+			{{ range .Imports -}}
+				"{{ . }}"
+			{{ end -}}
+			{{ if .SharedImport -}}
+				{{ .SharedPackage }} "{{ .SharedImport }}"
+			{{ end -}}
+		)
+	{{ end -}}
+
+	{{ if .SharedPackage -}}
+		// ValidationError and ValidationErrors are defined in the shared model package, so the validation errors
+		// of shared types and of the types of this package can be combined
+		type ValidationError = {{ .SharedPackage }}.ValidationError
+		type ValidationErrors = {{ .SharedPackage }}.ValidationErrors
+
+		// NewValidationError returns a validation error with a message describing the failed rule
+		var NewValidationError = {{ .SharedPackage }}.NewValidationError
+	{{ else -}}
+		// ValidationError describes a value that does not satisfy a validation rule of the swagger spec
+		type ValidationError struct {
+			// JSON pointer to the invalid value, relative to the body or parameter
+			Path string 'json:"path"'
+			// Location of the invalid value: body, path, query or header; empty when validating a model directly
+			Location string 'json:"location,omitempty"'
+			// The rule that failed, e.g. required, maxLength, pattern or enum
+			Rule string 'json:"rule"'
+			// The limit set by the rule, e.g. the maximum length or the allowed values
+			Limit interface{} 'json:"limit,omitempty"'
+			// The actual value
+			Value interface{} 'json:"value,omitempty"'
+			// Human readable description of the error
+			Message string 'json:"message"'
+		}
+
+		// Error returns the message of the validation error
+		func (e ValidationError) Error() string {
+			return e.Message
+		}
+
+		// ValidationErrors is a list of validation errors
+		type ValidationErrors []ValidationError
+
+		// Strings returns the messages of the validation errors
+		func (errs ValidationErrors) Strings() []string {
+			messages := make([]string, len(errs))
+			for i := range errs {
+				messages[i] = errs[i].Message
+			}
+			return messages
+		}
+
+		// WithLocation sets the location of all validation errors
+		func (errs ValidationErrors) WithLocation(location string) ValidationErrors {
+			for i := range errs {
+				errs[i].Location = location
+			}
+			return errs
+		}
+	{{ end -}}
+
+	// propertyName returns the name of a property of the value called name, for use in messages
+	func propertyName(name, property string) string {
+		if name == "" {
+			return property
+		}
+		return name + "." + property
+	}
+
+	// isMultipleOf checks whether value is a multiple of factor, allowing for floating point rounding errors
+	func isMultipleOf(value, factor float64) bool {
+		quotient := value / factor
+		return math.Abs(quotient-math.Round(quotient)) < 1e-9
+	}
+
+	{{ if not .SharedPackage -}}
+		// NewValidationError returns a validation error with a message describing the failed rule
+		// name is the human readable name of the value, as used in the message; empty for the root object
+		func NewValidationError(path, name, rule string, limit, value interface{}) ValidationError {
+			var message string
+
+			if name == "" {
+				name = "object"
+			}
+
+			switch rule {
+			case "required":
+				message = fmt.Sprintf("%s is required", name)
+			case "enum":
+				message = fmt.Sprintf("%v is not an allowed value for %s", value, name)
+			case "maximum":
+				message = fmt.Sprintf("%s should be at most %v", name, limit)
+			case "exclusiveMaximum":
+				message = fmt.Sprintf("%s should be less than %v", name, limit)
+			case "minimum":
+				message = fmt.Sprintf("%s should be at least %v", name, limit)
+			case "exclusiveMinimum":
+				message = fmt.Sprintf("%s should be more than %v", name, limit)
+			case "type":
+				message = fmt.Sprintf("%s should be of type %v", name, limit)
+			case "multipleOf":
+				message = fmt.Sprintf("%s should be a multiple of %v", name, limit)
+			case "maxProperties":
+				message = fmt.Sprintf("%s should have no more than %v properties", name, limit)
+			case "minProperties":
+				message = fmt.Sprintf("%s should have no less than %v properties", name, limit)
+			case "maxLength":
+				message = fmt.Sprintf("%s should be no longer than %v characters", name, limit)
+			case "minLength":
+				message = fmt.Sprintf("%s should be no shorter than %v characters", name, limit)
+			case "pattern":
+				message = fmt.Sprintf("%s should match the regex %v", name, limit)
+			case "maxItems":
+				message = fmt.Sprintf("%s should have no more than %v elements", name, limit)
+			case "minItems":
+				message = fmt.Sprintf("%s should have no less than %v elements", name, limit)
+			case "uniqueItems":
+				if value != nil {
+					message = fmt.Sprintf("%v occurs multiple times in %s", value, name)
+				} else {
+					message = fmt.Sprintf("%s contains duplicate elements", name)
+				}
+			case "format":
+				message = fmt.Sprintf("%s should be a valid %v", name, limit)
+			case "writeOnly":
+				message = fmt.Sprintf("%s is write-only and cannot be in a response", name)
+			default:
+				message = fmt.Sprintf("%s does not satisfy %s", name, rule)
+			}
+
+			return ValidationError{
+				Path:    path,
+				Rule:    rule,
+				Limit:   limit,
+				Value:   value,
+				Message: message,
+			}
+		}
+	{{ end -}}
+
 	{{ range .Types -}}
 		{{ if or .IsStruct .IsSlice -}}
 			{{ template "validateType" dict "Type" . "ReadOnly" "" }}
@@ -237,17 +442,45 @@ var Validate = parse("validate", `
 			{{ end -}}
 		{{ else -}}
 			// Validate validates a {{ .Name }} based on the swagger spec
-			func (s *{{ .Name }}) Validate() (errors []string) {
-				{{ if eq .Type "int64" -}}
-					{{ template "validateInt64" dict "Validation" .Validation.Int "Int" "int64(*s)" "Name" .Name -}}
-				{{ else if eq .Type "float64" -}}
-					{{ template "validateFloat64" dict "Validation" .Validation.Number "Number" "float64(*s)" "Name" .Name -}}
+			func (s *{{ .Name }}) Validate() ValidationErrors {
+				return s.validateAt("", "{{ if not (and .Ref .Ref.IsStruct) }}{{ .Name }}{{ end }}")
+			}
+
+			// validateAt validates a {{ .Name }} that is located at path, and is called name in messages
+			func (s *{{ .Name }}) validateAt(path, name string) (errors ValidationErrors) {
+				{{ if .Validation.Custom -}}
+					{{ template "validateCustom" dict "Validation" .Validation.Custom "Value" (printf "%s(*s)" .Type) "Path" "path" "Name" "name" -}}
+				{{ else if eq .Type "int32" "int64" "uint32" "uint64" -}}
+					{{ template "validateInteger" dict "Validation" .Validation.Int "Int" (printf "%s(*s)" .Type) "Path" "path" "Name" "name" -}}
+				{{ else if eq .Type "float32" "float64" -}}
+					{{ template "validateNumber" dict "Validation" .Validation.Number "Number" (printf "%s(*s)" .Type) "Path" "path" "Name" "name" -}}
 				{{ else if eq .Type "string" -}}
-					{{ template "validateString" dict "Validation" .Validation.String "String" "string(*s)" "Name" .Name "RegexpName" .Name -}}
+					{{ template "validateString" dict "Validation" .Validation.String "String" "string(*s)" "Path" "path" "Name" "name" "RegexpName" .Name -}}
+				{{ else if .Ref -}}
+					errors = (*{{ .Ref.Name }})(s).{{ template "validateAt" .HasSharedType }}(path, name)
 				{{ end }}
 
 				return
 			}
+
+			{{ if .HasReadOnlyProps -}}
+				// Validate validates a ReadOnly{{ .Name }} based on the swagger spec
+				func (s *ReadOnly{{ .Name }}) Validate() ValidationErrors {
+					return s.validateAt("", "{{ if not .Ref.IsStruct }}{{ .Name }}{{ end }}")
+				}
+
+				// validateAt validates a ReadOnly{{ .Name }} that is located at path, and is called name in messages
+				func (s *ReadOnly{{ .Name }}) validateAt(path, name string) ValidationErrors {
+					return (*ReadOnly{{ .Ref.Name }})(s).{{ template "validateAt" .HasSharedType }}(path, name)
+				}
+			{{ end -}}
+		{{ end -}}
+
+		{{ if $.IsShared -}}
+			{{ template "exportedValidateAt" .Name }}
+			{{ if .HasReadOnlyProps -}}
+				{{ template "exportedValidateAt" (print "ReadOnly" .Name) }}
+			{{ end -}}
 		{{ end -}}
 	{{ end }}
 